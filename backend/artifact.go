@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Artifact is the saved form of a compiled contract, and of its deployment if it is deployed.
+//Info holds the ABI, the compiler information and the metadata.
+//Address is saved for a contract bound by At too, whose deployment is not known.
+type Artifact struct {
+	File            string                 `json:"file"`
+	Name            string                 `json:"name"`
+	Info            *compiler.ContractInfo `json:"info"`
+	Code            hexutil.Bytes          `json:"code"`
+	RuntimeCode     hexutil.Bytes          `json:"runtimeCode"`
+	Address         *common.Address        `json:"address,omitempty"`
+	BlockDeployed   *hexutil.Big           `json:"blockDeployed,omitempty"`
+	Deployer        *common.Address        `json:"deployer,omitempty"`
+	DeployNonce     hexutil.Uint64         `json:"deployNonce,omitempty"`
+	DeployGas       hexutil.Uint64         `json:"deployGas,omitempty"`
+	ConstructorArgs hexutil.Bytes          `json:"constructorArgs,omitempty"` //ABI encoded
+}
+
+//Artifact returns the artifact of the contract.
+func (p *Contract) Artifact() (*Artifact, error) {
+	if p.Info == nil {
+		return nil, fmt.Errorf("%s contract has no compiler information", p.Name)
+	}
+	r := &Artifact{
+		File:        p.File,
+		Name:        p.Name,
+		Info:        p.Info,
+		Code:        p.Code,
+		RuntimeCode: p.RuntimeCode,
+	}
+	if p.deployed() {
+		address := p.Address
+		r.Address = &address
+	}
+	if p.BlockDeployed != nil {
+		r.BlockDeployed = (*hexutil.Big)(p.BlockDeployed)
+		deployer := p.Deployer
+		r.Deployer = &deployer
+		r.DeployNonce = hexutil.Uint64(p.DeployNonce)
+		r.DeployGas = hexutil.Uint64(p.DeployGas)
+
+		args, err := p.Abi.Pack("", p.ConstructorInputs...)
+		if err != nil {
+			return nil, fmt.Errorf("constructor inputs: %v", err)
+		}
+		r.ConstructorArgs = args
+	}
+	return r, nil
+}
+
+//Save writes the artifact of the contract as JSON to the path.
+func (p *Contract) Save(path string) error {
+	artifact, err := p.Artifact()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+//LoadContract reads the artifact saved to the path, and returns its contract without compiling it.
+//If b is nil, the contract is attached to a new backend and is not deployed, like a new contract.
+//Otherwise it is attached to b with the deployment of the artifact.
+func LoadContract(path string, b *Backend) (*Contract, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	artifact := &Artifact{}
+	if err := json.Unmarshal(raw, artifact); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	r, err := artifact.Contract(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+//Contract returns the contract of the artifact, attached to b like LoadContract.
+//The owner key is derived from the Seed like NewContract.
+func (p *Artifact) Contract(b *Backend) (*Contract, error) {
+	if p.Info == nil {
+		return nil, fmt.Errorf("no compiler information")
+	}
+	abi, err := newAbi(p.Info.AbiDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Contract{
+		File:        p.File,
+		Name:        p.Name,
+		Info:        p.Info,
+		Abi:         abi,
+		Code:        p.Code,
+		RuntimeCode: p.RuntimeCode,
+	}
+	r.OwnerKey = ownerKey(p.File, p.Name)
+	r.Owner = crypto.PubkeyToAddress(r.OwnerKey.PublicKey)
+
+	if b == nil {
+		r.Backend = NewBackend()
+		return r, nil
+	}
+	r.Backend = b
+	if p.Address != nil {
+		r.Address = *p.Address
+	}
+	if p.BlockDeployed != nil {
+		r.BlockDeployed = p.BlockDeployed.ToInt()
+		r.DeployGas = uint64(p.DeployGas)
+	}
+	if p.Deployer != nil {
+		r.Deployer = *p.Deployer
+		r.DeployNonce = uint64(p.DeployNonce)
+	}
+	if len(p.ConstructorArgs) > 0 {
+		if r.ConstructorInputs, err = abi.Constructor.Inputs.UnpackValues(p.ConstructorArgs); err != nil {
+			return nil, fmt.Errorf("constructor inputs: %v", err)
+		}
+	}
+	return r, nil
+}
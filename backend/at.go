@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//At compiles the contract and binds it to the address on the backend, where it is already deployed
+//by another contract, a factory or a genesis file.
+func At(file, name string, b *Backend, address common.Address) (*Contract, error) {
+	c, err := Compile(file, name)
+	if err != nil {
+		return nil, err
+	}
+	c.Backend = b
+	return c.At(address)
+}
+
+//AtArtifact binds the contract of the artifact file to the address on the backend, like At.
+func AtArtifact(path string, b *Backend, address common.Address) (*Contract, error) {
+	if b == nil {
+		return nil, fmt.Errorf("no backend for %s", path)
+	}
+	c, err := LoadContract(path, b)
+	if err != nil {
+		return nil, err
+	}
+	return c.At(address)
+}
+
+//At returns a copy of the contract bound to the address on p.Backend, checking that there is code at the address.
+//The deployment is not known, so BlockDeployed is nil and Deployer and ConstructorInputs are empty.
+func (p *Contract) At(address common.Address) (*Contract, error) {
+	if p.Backend == nil {
+		return nil, fmt.Errorf("%s contract has no backend", p.Name)
+	}
+	code, err := p.Backend.CodeAt(context.Background(), address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract code at %s", address.Hex())
+	}
+
+	r := *p
+	r.ConstructorInputs = nil
+	r.Address = address
+	r.BlockDeployed = nil
+	r.DeployGas = 0
+	r.Deployer = common.Address{}
+	r.DeployNonce = 0
+	return &r, nil
+}
@@ -0,0 +1,242 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+type (
+	//bindArg is a method input, method output or event input in the generated code.
+	bindArg struct {
+		Name    string //parameter name
+		Field   string //struct field name
+		Type    string
+		Indexed bool
+	}
+	bindMethod struct {
+		Name    string //method name in the ABI
+		GoName  string
+		Const   bool
+		Inputs  []bindArg
+		Outputs []bindArg
+	}
+	bindEvent struct {
+		Name   string
+		GoName string
+		Inputs []bindArg
+	}
+	bindContract struct {
+		Package     string
+		Type        string
+		Constructor []bindArg
+		Methods     []bindMethod
+		Events      []bindEvent
+	}
+)
+
+//Bind generates the Go source code of typed wrappers of the contract on top of Contract.
+//The generated type has a method for each contract's method, a struct for the outputs
+//of methods returning several values, and a struct and parsers for each event.
+//A method whose name is a field or method of Contract gets the suffix Method, like NameMethod for name(),
+//so the fields and methods of the embedded Contract are not hidden.
+func (p *Contract) Bind(pkg string) (string, error) {
+	data := bindContract{
+		Package:     pkg,
+		Type:        abi.ToCamelCase(p.Name),
+		Constructor: bindArgs(p.Abi.Constructor.Inputs),
+	}
+
+	names := []string{}
+	for name := range p.Abi.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := p.Abi.Methods[name]
+		goName := abi.ToCamelCase(name)
+		if bindPromoted[goName] {
+			goName += "Method"
+		}
+		data.Methods = append(data.Methods, bindMethod{
+			Name:    name,
+			GoName:  goName,
+			Const:   m.Const,
+			Inputs:  bindArgs(m.Inputs),
+			Outputs: bindArgs(m.Outputs),
+		})
+	}
+
+	names = []string{}
+	for name := range p.Abi.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data.Events = append(data.Events, bindEvent{
+			Name:   name,
+			GoName: abi.ToCamelCase(name),
+			Inputs: bindArgs(p.Abi.Events[name].Inputs),
+		})
+	}
+
+	buf := bytes.Buffer{}
+	if err := bindTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, buf.String())
+	}
+	return string(code), nil
+}
+
+//bindArgs converts ABI arguments to the names and Go types used in the generated code.
+func bindArgs(args abi.Arguments) []bindArg {
+	ret := make([]bindArg, len(args))
+	for i, a := range args {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		field := abi.ToCamelCase(name)
+		param := strings.ToLower(field[:1]) + field[1:]
+		if token.Lookup(param).IsKeyword() || bindReserved[param] {
+			param += "_"
+		}
+		typ := a.Type.Type.String()
+		if a.Indexed && hashedInTopic(a.Type) {
+			typ = "common.Hash"
+		}
+		ret[i] = bindArg{Name: param, Field: field, Type: typ, Indexed: a.Indexed}
+	}
+	return ret
+}
+
+//bindReserved holds the identifiers used in the generated methods.
+var bindReserved = map[string]bool{"p": true, "key": true, "contract": true, "out": true, "ret": true, "err": true}
+
+//bindPromoted holds the names promoted from the embedded Contract to the generated type.
+var bindPromoted = func() map[string]bool {
+	r := map[string]bool{"Contract": true}
+	t := reflect.TypeOf(&Contract{})
+	for i := 0; i < t.NumMethod(); i++ {
+		r[t.Method(i).Name] = true
+	}
+	for i := 0; i < t.Elem().NumField(); i++ {
+		r[t.Elem().Field(i).Name] = true
+	}
+	return r
+}()
+
+var bindTemplate = template.Must(template.New("bind").Parse(`// Code generated by contract-bindgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ *ecdsa.PrivateKey
+)
+
+{{$type := .Type}}
+//{{$type}} is a typed wrapper of the {{$type}} contract.
+type {{$type}} struct {
+	*backend.Contract
+}
+
+//New{{$type}} wraps a compiled contract.
+func New{{$type}}(contract *backend.Contract) *{{$type}} {
+	return &{{$type}}{Contract: contract}
+}
+
+//Deploy{{$type}} deploys the compiled contract with typed constructor's inputs.
+func Deploy{{$type}}(contract *backend.Contract{{range .Constructor}}, {{.Name}} {{.Type}}{{end}}) (*{{$type}}, error) {
+	if err := contract.Deploy({{range $i, $a := .Constructor}}{{if $i}}, {{end}}{{.Name}}{{end}}); err != nil {
+		return nil, err
+	}
+	return New{{$type}}(contract), nil
+}
+{{range .Methods}}
+{{if and .Const (gt (len .Outputs) 1)}}
+//{{$type}}{{.GoName}}Output holds the outputs of {{.Name}}.
+type {{$type}}{{.GoName}}Output struct {
+{{range .Outputs}}	{{.Field}} {{.Type}}
+{{end}}}
+
+//{{.GoName}} calls {{.Name}}.
+func (p *{{$type}}) {{.GoName}}({{range $i, $a := .Inputs}}{{if $i}}, {{end}}{{.Name}} {{.Type}}{{end}}) (*{{$type}}{{.GoName}}Output, error) {
+	ret, err := p.Contract.LowCall("{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+	out := &{{$type}}{{.GoName}}Output{}
+{{range $i, $o := .Outputs}}	out.{{.Field}} = ret[{{$i}}].({{.Type}})
+{{end}}	return out, nil
+}
+{{else if and .Const (eq (len .Outputs) 1)}}
+//{{.GoName}} calls {{.Name}}.
+func (p *{{$type}}) {{.GoName}}({{range $i, $a := .Inputs}}{{if $i}}, {{end}}{{.Name}} {{.Type}}{{end}}) ({{(index .Outputs 0).Type}}, error) {
+	var out {{(index .Outputs 0).Type}}
+	err := p.Contract.Call(&out, "{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+	return out, err
+}
+{{else if .Const}}
+//{{.GoName}} calls {{.Name}}.
+func (p *{{$type}}) {{.GoName}}({{range $i, $a := .Inputs}}{{if $i}}, {{end}}{{.Name}} {{.Type}}{{end}}) error {
+	_, err := p.Contract.LowCall("{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+	return err
+}
+{{else}}
+//{{.GoName}} sends a transaction of {{.Name}} signed with key, the owner's key if nil.
+func (p *{{$type}}) {{.GoName}}(key *ecdsa.PrivateKey{{range .Inputs}}, {{.Name}} {{.Type}}{{end}}) (*types.Receipt, error) {
+	return p.Contract.Execute(key, "{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+}
+{{end}}{{end}}
+{{range .Events}}
+//{{$type}}{{.GoName}} is the {{.Name}} event.
+type {{$type}}{{.GoName}} struct {
+{{range .Inputs}}	{{.Field}} {{.Type}}
+{{end}}	Raw *types.Log
+}
+
+//Parse{{.GoName}} unpacks a {{.Name}} event log.
+func (p *{{$type}}) Parse{{.GoName}}(log *types.Log) (*{{$type}}{{.GoName}}, error) {
+	event := &{{$type}}{{.GoName}}{Raw: log}
+	if err := p.Contract.UnpackLog(event, "{{.Name}}", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+//Filter{{.GoName}} returns the {{.Name}} events in the receipt.
+func (p *{{$type}}) Filter{{.GoName}}(receipt *types.Receipt) ([]*{{$type}}{{.GoName}}, error) {
+	events := []*{{$type}}{{.GoName}}{}
+	for _, log := range p.Contract.LogsOf("{{.Name}}", receipt) {
+		event, err := p.Parse{{.GoName}}(log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+{{end}}`))
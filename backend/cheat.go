@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Cheat is a change of an account written straight into the state by a cheatcode.
+type Cheat struct {
+	Kind    string         `json:"kind"` //balance, nonce, code or storage
+	Account common.Address `json:"account"`
+	Slot    *common.Hash   `json:"slot,omitempty"` //storage slot
+	Value   hexutil.Bytes  `json:"value"`
+}
+
+func (p Cheat) apply(statedb *state.StateDB) error {
+	switch p.Kind {
+	case "balance":
+		statedb.SetBalance(p.Account, new(big.Int).SetBytes(p.Value))
+	case "nonce":
+		statedb.SetNonce(p.Account, new(big.Int).SetBytes(p.Value).Uint64())
+	case "code":
+		statedb.SetCode(p.Account, p.Value)
+	case "storage":
+		if p.Slot == nil {
+			return fmt.Errorf("storage cheat of %s has no slot", p.Account.Hex())
+		}
+		statedb.SetState(p.Account, *p.Slot, common.BytesToHash(p.Value))
+	default:
+		return fmt.Errorf("unknown cheat: %s", p.Kind)
+	}
+	return nil
+}
+
+//cheatsOf returns the cheats which made the block, or nil if it is a block of transactions.
+func (p *Backend) cheatsOf(block common.Hash) []Cheat {
+	p.recordLock.Lock()
+	defer p.recordLock.Unlock()
+	return p.cheats[block]
+}
+
+//applyCheats writes the cheats into the state of the head block of the backend,
+//and makes a new block without transactions with the state.
+//It fails if transactions are pending, which would be dropped.
+//The block is recorded in the Journal with the cheats, so it is made again by Replay.
+func applyCheats(b *Backend, changes ...Cheat) error {
+	defer LockBackend(b)()
+	return writeCheats(b, changes)
+}
+
+//writeCheats applies the cheats like applyCheats, while the caller holds the lock of the backend.
+func writeCheats(b *Backend, changes []Cheat) error {
+	b.recordLock.Lock()
+	pending := b.pending
+	b.recordLock.Unlock()
+	if pending > 0 {
+		return fmt.Errorf("%d pending transactions are not committed", pending)
+	}
+
+	chain := b.Blockchain()
+	parent := chain.CurrentBlock()
+	statedb, err := chain.StateAt(parent.Root())
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := c.apply(statedb); err != nil {
+			return err
+		}
+	}
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Time:       parent.Time() + 10, //as the blocks of the simulated backend
+	}
+	header.Difficulty = chain.Engine().CalcDifficulty(chain, header.Time, parent.Header())
+	header.Root = statedb.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	block := types.NewBlock(header, nil, nil, nil)
+	if _, err := chain.WriteBlockWithState(block, nil, nil, statedb, true); err != nil {
+		return err
+	}
+	b.Rollback() //the pending block on the new head
+
+	b.recordLock.Lock()
+	if b.cheats == nil {
+		b.cheats = map[common.Hash][]Cheat{}
+	}
+	b.cheats[block.Hash()] = changes
+	b.recordLock.Unlock()
+	return nil
+}
+
+//SetBalance sets the ether balance of the account on the backend.
+func SetBalance(b *Backend, account common.Address, balance *big.Int) error {
+	return applyCheats(b, Cheat{Kind: "balance", Account: account, Value: balance.Bytes()})
+}
+
+//SetNonce sets the nonce of the account on the backend.
+func SetNonce(b *Backend, account common.Address, nonce uint64) error {
+	return applyCheats(b, Cheat{Kind: "nonce", Account: account, Value: new(big.Int).SetUint64(nonce).Bytes()})
+}
+
+//SetCode sets the runtime code of the account on the backend.
+func SetCode(b *Backend, account common.Address, code []byte) error {
+	return applyCheats(b, Cheat{Kind: "code", Account: account, Value: code})
+}
+
+//SetStorage sets the storage slot of the account on the backend.
+func SetStorage(b *Backend, account common.Address, slot, value common.Hash) error {
+	return applyCheats(b, Cheat{Kind: "storage", Account: account, Slot: &slot, Value: value.Bytes()})
+}
+
+//SetVariable sets the state variable of the contract by its label in the storage layout,
+//or by "Contract.label" if contracts in the inheritance chain declare variables of the same label.
+//The value is right aligned like common.BigToHash, and only the bytes of the variable are written in a packed slot.
+//For a mapping, keys are its keys padded to 32 bytes, one for each level, and the value takes the whole slot.
+func (p *Contract) SetVariable(label string, value common.Hash, keys ...common.Hash) error {
+	layout, err := p.StorageLayout()
+	if err != nil {
+		return err
+	}
+	v, err := layout.Lookup(label)
+	if err != nil {
+		return fmt.Errorf("%s: %v", p.Name, err)
+	}
+
+	slot := common.BigToHash(new(big.Int).SetUint64(v.Slot))
+	isMapping := strings.HasPrefix(v.Type, "t_mapping")
+	switch {
+	case isMapping && len(keys) == 0:
+		return fmt.Errorf("%s is a mapping without keys", label)
+	case isMapping == false && len(keys) > 0:
+		return fmt.Errorf("%s is not a mapping", label)
+	case isMapping:
+		for _, key := range keys {
+			slot = crypto.Keccak256Hash(key.Bytes(), slot.Bytes())
+		}
+		return SetStorage(p.Backend, p.Address, slot, value)
+	}
+
+	if v.Bytes > 32 {
+		return fmt.Errorf("%s takes %d bytes, set its slots with SetStorage", label, v.Bytes)
+	}
+	if v.Bytes == 32 {
+		return SetStorage(p.Backend, p.Address, slot, value)
+	}
+
+	//the other variables in the packed slot are read and written under the lock
+	defer LockBackend(p.Backend)()
+	current, err := p.Backend.StorageAt(context.Background(), p.Address, slot, nil)
+	if err != nil {
+		return err
+	}
+	packed := common.BytesToHash(current)
+	copy(packed[32-v.Offset-v.Bytes:32-v.Offset], value[32-v.Bytes:])
+	return writeCheats(p.Backend, []Cheat{{Kind: "storage", Account: p.Address, Slot: &slot, Value: packed.Bytes()}})
+}
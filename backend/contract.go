@@ -18,35 +18,48 @@ import (
 )
 
 //Contract struct holds data before compilation and information after compilation.
+//A deployed Contract can be used from several goroutines, see LockBackend.
 type Contract struct {
 	File              string
 	Name              string
-	Backend           *backends.SimulatedBackend
+	Backend           *Backend
 	OwnerKey          *ecdsa.PrivateKey
 	Owner             common.Address
 	Info              *compiler.ContractInfo
 	ConstructorInputs []interface{}
 	Abi               *abi.ABI
 	Code              []byte
+	RuntimeCode       []byte
 	Address           common.Address
 	BlockDeployed     *big.Int
+	DeployGas         uint64         //gas used by the deploy tx
+	Deployer          common.Address //account of the deploy tx
+	DeployNonce       uint64         //nonce of the deploy tx, which makes the address with Deployer
+	Lenient           bool           //converts the arguments of Deploy, Call and Execute by ConvertArgs
 }
 
 //NewContract is to create simulatied backend and compile solidity code
+//The owner key is derived from the Seed and the contract, so it is the same in a reproduced run.
 func NewContract(file, name string) (*Contract, error) {
+	r, err := Compile(file, name)
+	if err != nil {
+		return nil, err
+	}
+	r.Backend = NewBackend()
+	return r, nil
+}
 
-	ownerKey, _ := crypto.GenerateKey()
+//Compile compiles the contract like NewContract without a backend.
+//Backend is to be set before the contract is deployed or bound with At.
+func Compile(file, name string) (*Contract, error) {
+
+	key := ownerKey(file, name)
 
 	r := &Contract{
-		File: file,
-		Name: name,
-		//creates a new binding backend using a simulated blockchain
-		Backend: backends.NewSimulatedBackend(
-			nil,
-			10000000,
-		),
-		OwnerKey: ownerKey,
-		Owner:    crypto.PubkeyToAddress(ownerKey.PublicKey),
+		File:     file,
+		Name:     name,
+		OwnerKey: key,
+		Owner:    crypto.PubkeyToAddress(key.PublicKey),
 	}
 	//compile
 	if err := r.compile(); err != nil {
@@ -56,45 +69,131 @@ func NewContract(file, name string) (*Contract, error) {
 	return r, nil
 }
 
+//deployed reports whether the contract has an address, deployed by Deploy or bound by At.
+func (p *Contract) deployed() bool {
+	return p.Address != (common.Address{})
+}
+
+//ownerKey derives the owner key of the contract from the Seed.
+func ownerKey(file, name string) *ecdsa.PrivateKey {
+	return DeriveKey(Seed(), fmt.Sprintf("owner/%s:%s", file, name))
+}
+
+//NewBackend creates a new binding backend using a simulated blockchain
+func NewBackend() *Backend {
+	return WrapBackend(backends.NewSimulatedBackend(
+		nil,
+		10000000,
+	))
+}
+
+//Clone returns a copy of the compiled contract attached to a new simulated backend.
+//The copy is not deployed yet and shares the owner key with p.
+func (p *Contract) Clone() *Contract {
+	r := *p
+	r.Backend = NewBackend()
+	r.ConstructorInputs = nil
+	r.Address = common.Address{}
+	r.BlockDeployed = nil
+	r.DeployGas = 0
+	r.Deployer = common.Address{}
+	r.DeployNonce = 0
+	return &r
+}
+
 func (p *Contract) compile() error {
 	contracts, err := compiler.CompileSolidity("", p.File)
 	if err != nil {
 		return err
 	}
+	return p.setCompiled(contracts, p.File)
+}
 
+//setCompiled sets the contract named p.Name compiled from the source to p.
+func (p *Contract) setCompiled(contracts map[string]*compiler.Contract, source string) error {
 	//Get the contract to test from the compiled contracts.
-	contract, ok := contracts[fmt.Sprintf("%s:%s", p.File, p.Name)]
+	contract, ok := contracts[fmt.Sprintf("%s:%s", source, p.Name)]
 	if ok == false {
-		fmt.Errorf("%s contract is not here", p.Name)
+		return fmt.Errorf("%s contract is not here", p.Name)
 	}
 	//make abi.ABI instance
-	abiBytes, err := json.Marshal(contract.Info.AbiDefinition)
-	if err != nil {
-		return err
-	}
-	abi, err := abi.JSON(strings.NewReader(string(abiBytes)))
+	abi, err := newAbi(contract.Info.AbiDefinition)
 	if err != nil {
 		return err
 	}
 	p.Info = &contract.Info
-	p.Abi = &abi
+	p.Abi = abi
 	p.Code = common.FromHex(contract.Code)
+	p.RuntimeCode = common.FromHex(contract.RuntimeCode)
 	return nil
 }
 
+//newAbi makes abi.ABI instance from the ABI definition of the compiler information.
+func newAbi(definition interface{}) (*abi.ABI, error) {
+	abiBytes, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+	r, err := abi.JSON(strings.NewReader(string(abiBytes)))
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+//convert converts the arguments of the method, or the constructor if method is empty, when p.Lenient is set.
+func (p *Contract) convert(method string, args []interface{}) ([]interface{}, error) {
+	if p.Lenient == false {
+		return args, nil
+	}
+	inputs := p.Abi.Constructor.Inputs
+	if method != "" {
+		m, ok := p.Abi.Methods[method]
+		if ok == false {
+			return nil, fmt.Errorf("method '%s' not found", method)
+		}
+		inputs = m.Inputs
+	}
+	return ConvertArgs(inputs, args)
+}
+
+//pack converts the arguments and packs them with the method's id.
+func (p *Contract) pack(method string, args ...interface{}) ([]byte, error) {
+	args, err := p.convert(method, args)
+	if err != nil {
+		return nil, err
+	}
+	return p.Abi.Pack(method, args...)
+}
+
 //Deploy makes creation contract tx and receives the result by receit.
+//The tx takes the pending nonce of the owner, so the contract can be deployed again on the same chain,
+//and Deployer and DeployNonce record which account and nonce made the address.
 func (p *Contract) Deploy(args ...interface{}) error {
-	input, err := p.Abi.Pack("", args...) //constructor's inputs
+	defer LockBackend(p.Backend)()
+	return p.deploy(args...)
+}
+
+//deploy deploys the contract like Deploy, while the caller holds the lock of the backend.
+func (p *Contract) deploy(args ...interface{}) error {
+	code, args, err := p.initCode(args...)
 	if err != nil {
 		return err
 	}
 
-	p.ConstructorInputs = args // Save for later checkout
+	deployer := crypto.PubkeyToAddress(p.OwnerKey.PublicKey)
+	nonce, err := p.Backend.PendingNonceAt(context.Background(), deployer)
+	if err != nil {
+		return err
+	}
 
 	//make tx for contract creation
-	tx := types.NewContractCreation(0, big.NewInt(0), 3000000, big.NewInt(0), append(p.Code, input...))
+	tx := types.NewContractCreation(nonce, big.NewInt(0), 3000000, big.NewInt(0), code)
 	//signing
-	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, p.OwnerKey)
+	tx, err = types.SignTx(tx, types.HomesteadSigner{}, p.OwnerKey)
+	if err != nil {
+		return err
+	}
 	//sned tx to simulated backend
 	if err := p.Backend.SendTransaction(context.Background(), tx); err != nil {
 		return err
@@ -110,15 +209,30 @@ func (p *Contract) Deploy(args ...interface{}) error {
 	if receipt.Status != 1 {
 		return fmt.Errorf("status of deploy tx receipt: %v", receipt.Status)
 	}
+
+	p.ConstructorInputs = args // Save for later checkout
 	//get contract's address and block deployed from the receipt
 	p.Address = receipt.ContractAddress
 	p.BlockDeployed = receipt.BlockNumber
+	p.DeployGas = receipt.GasUsed
+	p.Deployer = deployer
+	p.DeployNonce = nonce
 	return nil
 }
 
+//Redeploy deploys another instance of the contract on the same chain with the args,
+//and returns it. p is not changed.
+func (p *Contract) Redeploy(args ...interface{}) (*Contract, error) {
+	r := *p
+	if err := r.Deploy(args...); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
 // Call is Invokes a view method with args and then receive the result unpacked.
 func (p *Contract) Call(result interface{}, method string, args ...interface{}) error {
-	if input, err := p.Abi.Pack(method, args...); err != nil {
+	if input, err := p.pack(method, args...); err != nil {
 		return err
 	} else {
 		msg := ethereum.CallMsg{From: common.Address{}, To: &p.Address, Data: input}
@@ -135,7 +249,7 @@ func (p *Contract) Call(result interface{}, method string, args ...interface{})
 
 //LowCall returns method's output in a different way than Call.
 func (p *Contract) LowCall(method string, args ...interface{}) ([]interface{}, error) {
-	if input, err := p.Abi.Pack(method, args...); err != nil {
+	if input, err := p.pack(method, args...); err != nil {
 		return nil, err
 	} else {
 		msg := ethereum.CallMsg{From: common.Address{}, To: &p.Address, Data: input}
@@ -151,25 +265,57 @@ func (p *Contract) LowCall(method string, args ...interface{}) ([]interface{}, e
 	}
 }
 
+//LowCallFrom returns method's output like LowCall, calling it from the given sender.
+func (p *Contract) LowCallFrom(from common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	out, err := p.callFrom(from, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return p.Abi.Methods[method].Outputs.UnpackValues(out)
+}
+
 //Execute executes the contract's method. For that, take tx with singer's key, method and inputs,
 //and then send it to the simulated backend, and return the receipt.
+//The tx is mined alone in a new block under LockBackend, so Execute can be called from several goroutines.
 func (p *Contract) Execute(key *ecdsa.PrivateKey, method string, args ...interface{}) (*types.Receipt, error) {
 	if key == nil {
 		key = p.OwnerKey
 	}
 
-	data, err := p.Abi.Pack(method, args...)
+	data, err := p.pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.send(crypto.PubkeyToAddress(key.PublicKey), data, func(tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignTx(tx, types.HomesteadSigner{}, key)
+	})
+}
+
+//ExecuteAs executes the contract's method like Execute, impersonating the sender without its key.
+//The tx has a fake signature which is not checked by the simulated backend, see Impersonated.
+func (p *Contract) ExecuteAs(from common.Address, method string, args ...interface{}) (*types.Receipt, error) {
+	data, err := p.pack(method, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	nonce, err := p.Backend.PendingNonceAt(context.Background(), crypto.PubkeyToAddress(key.PublicKey))
+	return p.send(from, data, func(tx *types.Transaction) (*types.Transaction, error) {
+		return impersonate(tx, from)
+	})
+}
+
+//send makes the tx of the data from the account to the contract, signs it by sign,
+//and mines it alone in a new block.
+func (p *Contract) send(from common.Address, data []byte, sign func(*types.Transaction) (*types.Transaction, error)) (*types.Receipt, error) {
+	defer LockBackend(p.Backend)()
+
+	nonce, err := p.Backend.PendingNonceAt(context.Background(), from)
 	if err != nil {
 		return nil, err
 	}
 
-	tx, err := types.SignTx(types.NewTransaction(nonce, p.Address, new(big.Int), uint64(10000000), big.NewInt(0), data),
-		types.HomesteadSigner{}, key)
+	tx, err := sign(types.NewTransaction(nonce, p.Address, new(big.Int), uint64(10000000), big.NewInt(0), data))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+//units is the number of decimals of ether units to wei.
+var units = map[string]int64{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"ether":  18,
+}
+
+//ConvertArgs converts the arguments to the Go types demanded by abi.Pack for the inputs.
+//See ConvertValue for the accepted values.
+func ConvertArgs(inputs abi.Arguments, args []interface{}) ([]interface{}, error) {
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("argument count mismatch: %d for %d", len(args), len(inputs))
+	}
+	ret := make([]interface{}, len(args))
+	for i, input := range inputs {
+		v, err := ConvertValue(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s %s): %v", i, input.Name, input.Type.String(), err)
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+//ConvertValue converts the value to the Go type of the ABI type.
+//Integers accept Go integers, *big.Int, decimal and hex strings, and unit strings like "0.5 ether".
+//Addresses, bytes and fixed bytes accept hex strings, and booleans accept "true" and "false".
+//Slices and arrays accept slices of any of them.
+func ConvertValue(t abi.Type, value interface{}) (interface{}, error) {
+	v, err := convertValue(t, value)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func convertValue(t abi.Type, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, fmt.Errorf("nil value")
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type() == t.Type {
+		return rv, nil
+	}
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := toInteger(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return fitInteger(t, n)
+	case abi.BoolTy:
+		switch v := value.(type) {
+		case bool:
+			return reflect.ValueOf(v), nil
+		case string:
+			if v == "true" || v == "false" {
+				return reflect.ValueOf(v == "true"), nil
+			}
+		}
+	case abi.StringTy:
+		if v, ok := value.(string); ok == true {
+			return reflect.ValueOf(v), nil
+		}
+	case abi.AddressTy:
+		if v, ok := value.(string); ok == true {
+			if common.IsHexAddress(v) == false {
+				return reflect.Value{}, fmt.Errorf("invalid address: %q", v)
+			}
+			return reflect.ValueOf(common.HexToAddress(v)), nil
+		}
+	case abi.BytesTy:
+		if v, ok := value.(string); ok == true {
+			b, err := hexutil.Decode(v)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid bytes %q: %v", v, err)
+			}
+			return reflect.ValueOf(b), nil
+		}
+	case abi.FixedBytesTy:
+		ret := reflect.New(t.Type).Elem()
+		if v, ok := value.(string); ok == true {
+			b, err := hexutil.Decode(v)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid bytes %q: %v", v, err)
+			}
+			if len(b) > t.Size {
+				return reflect.Value{}, fmt.Errorf("%d bytes do not fit in bytes%d", len(b), t.Size)
+			}
+			reflect.Copy(ret, reflect.ValueOf(b))
+			return ret, nil
+		}
+		if rv.Kind() == reflect.Array && rv.Len() == t.Size && rv.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(ret, rv) //common.Hash for bytes32 and so on
+			return ret, nil
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			break
+		}
+		ret := reflect.Value{}
+		if t.T == abi.SliceTy {
+			ret = reflect.MakeSlice(t.Type, rv.Len(), rv.Len())
+		} else if rv.Len() != t.Size {
+			return reflect.Value{}, fmt.Errorf("%d elements for %s", rv.Len(), t.String())
+		} else {
+			ret = reflect.New(t.Type).Elem()
+		}
+		for i := 0; i < rv.Len(); i++ {
+			e, err := convertValue(*t.Elem, rv.Index(i).Interface())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %v", i, err)
+			}
+			ret.Index(i).Set(e)
+		}
+		return ret, nil
+	}
+	return reflect.Value{}, fmt.Errorf("can not convert %T to %s", value, t.String())
+}
+
+//toInteger converts Go integers, big.Int and number strings to big.Int.
+func toInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("nil *big.Int")
+		}
+		return v, nil
+	case big.Int:
+		return &v, nil
+	case string:
+		return parseInteger(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("%T is not an integer", value)
+}
+
+//parseInteger parses a decimal or hex number, or a decimal number with an ether unit like "0.5 ether".
+func parseInteger(s string) (*big.Int, error) {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+		if n, ok := new(big.Int).SetString(fields[0], 0); ok == true {
+			return n, nil
+		}
+	case 2:
+		decimals, ok := units[strings.ToLower(fields[1])]
+		if ok == false {
+			return nil, fmt.Errorf("unknown unit: %q", fields[1])
+		}
+		r, ok := new(big.Rat).SetString(fields[0])
+		if ok == false {
+			break
+		}
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)))
+		if r.IsInt() == false {
+			return nil, fmt.Errorf("%q is not an integer in wei", s)
+		}
+		return new(big.Int).Set(r.Num()), nil
+	}
+	return nil, fmt.Errorf("invalid number: %q", s)
+}
+
+//fitInteger checks the range of the ABI integer type, and returns the value of its Go type.
+func fitInteger(t abi.Type, n *big.Int) (reflect.Value, error) {
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+	if t.T == abi.IntTy {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return reflect.Value{}, fmt.Errorf("%v does not fit in %s", n, t.String())
+	}
+
+	if t.Size > 64 {
+		return reflect.ValueOf(new(big.Int).Set(n)), nil
+	}
+	v := reflect.New(t.Type).Elem()
+	if t.T == abi.IntTy {
+		v.SetInt(n.Int64())
+	} else {
+		v.SetUint(n.Uint64())
+	}
+	return v, nil
+}
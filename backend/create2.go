@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//create2Source is the factory deploying contracts with CREATE2 for DeployCreate2.
+const create2Source = `pragma solidity >= 0.6.0 <0.7.0;
+
+contract Create2Factory {
+    event Deployed(address indexed addr, bytes32 indexed salt);
+
+    function deploy(bytes32 salt, bytes memory code) public payable returns (address addr) {
+        assembly { addr := create2(callvalue(), add(code, 32), mload(code), salt) }
+        require(addr != address(0), "Create2Factory: create2 failed");
+        emit Deployed(addr, salt);
+    }
+}
+`
+
+//create2Deployer is the account deploying the factory with its first tx.
+//Its key is derived from a fixed seed, so the factory has the same address on every chain.
+var (
+	create2DeployerKey = DeriveKey("contract-test", "create2-factory")
+	create2Deployer    = crypto.PubkeyToAddress(create2DeployerKey.PublicKey)
+)
+
+//Create2FactoryAddress is the address of the CREATE2 factory on every chain.
+var Create2FactoryAddress = crypto.CreateAddress(create2Deployer, 0)
+
+//Create2Factory deploys the CREATE2 factory on the backend if it is not there, and returns it.
+//The backend is locked from looking for the factory to deploying it, so it is deployed once by parallel callers.
+func Create2Factory(b *Backend) (*Contract, error) {
+	factory, err := newEmbedded(create2Source, "Create2Factory", b, create2DeployerKey)
+	if err != nil {
+		return nil, fmt.Errorf("compile create2 factory: %v", err)
+	}
+
+	defer LockBackend(b)()
+	code, err := b.CodeAt(context.Background(), Create2FactoryAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) > 0 {
+		factory.Address = Create2FactoryAddress
+		return factory, nil
+	}
+
+	if err := factory.deploy(); err != nil {
+		return nil, fmt.Errorf("deploy create2 factory: %v", err)
+	}
+	if factory.Address != Create2FactoryAddress {
+		return nil, fmt.Errorf("create2 factory is deployed at %s by nonce %d, not at %s",
+			factory.Address.Hex(), factory.DeployNonce, Create2FactoryAddress.Hex())
+	}
+	return factory, nil
+}
+
+//initCode returns the creation code of the contract with the constructor's inputs.
+func (p *Contract) initCode(args ...interface{}) ([]byte, []interface{}, error) {
+	args, err := p.convert("", args)
+	if err != nil {
+		return nil, nil, err
+	}
+	input, err := p.Abi.Pack("", args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(append([]byte{}, p.Code...), input...), args, nil
+}
+
+//Create2Address returns the address where DeployCreate2 deploys the contract with the salt and args.
+//The address depends only on the factory, the salt and the init code, so it is the same on every chain.
+func (p *Contract) Create2Address(salt common.Hash, args ...interface{}) (common.Address, error) {
+	code, _, err := p.initCode(args...)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.CreateAddress2(Create2FactoryAddress, salt, crypto.Keccak256(code)), nil
+}
+
+//DeployCreate2 deploys the contract with CREATE2 through the factory at Create2FactoryAddress,
+//deploying the factory first if it is not on the chain.
+//The owner sends the tx, but msg.sender of the constructor is the factory.
+//Deployer is the factory, and the same salt and args can not be deployed twice on a chain.
+func (p *Contract) DeployCreate2(salt common.Hash, args ...interface{}) error {
+	code, args, err := p.initCode(args...)
+	if err != nil {
+		return err
+	}
+	factory, err := Create2Factory(p.Backend)
+	if err != nil {
+		return err
+	}
+	address := crypto.CreateAddress2(factory.Address, salt, crypto.Keccak256(code))
+
+	receipt, err := factory.Execute(p.OwnerKey, "deploy", salt, code)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != 1 {
+		reason, _ := factory.RevertReason(p.Owner, "deploy", salt, code)
+		return fmt.Errorf("create2 deploy of %s at %s failed: %s", p.Name, address.Hex(), reason)
+	}
+
+	p.ConstructorInputs = args
+	p.Address = address
+	p.BlockDeployed = receipt.BlockNumber
+	p.DeployGas = receipt.GasUsed
+	p.Deployer = factory.Address
+	p.DeployNonce = 0
+	return nil
+}
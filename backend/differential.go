@@ -0,0 +1,158 @@
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Probe is a view method call used to compare the state of two contracts.
+type Probe struct {
+	Method string
+	Args   []interface{}
+}
+
+func (p Probe) String() string {
+	args := make([]string, len(p.Args))
+	for i, a := range p.Args {
+		args[i] = fmt.Sprint(a)
+	}
+	return fmt.Sprintf("%s(%s)", p.Method, strings.Join(args, ", "))
+}
+
+//Difference is a different result between the old and the new contract.
+//Step is the index of the step, -1 means the setup after deploy.
+type Difference struct {
+	Step int
+	Kind string //status, return, event or state
+	What string
+	Old  string
+	New  string
+}
+
+func (p Difference) String() string {
+	return fmt.Sprintf("step %d, %s of %s: old %s, new %s", p.Step, p.Kind, p.What, p.Old, p.New)
+}
+
+//Differential replays the same call sequence on two versions of a contract,
+//each deployed on its own backend, and reports the differences.
+type Differential struct {
+	Old        *Contract //compiled contracts
+	New        *Contract
+	DeployArgs []interface{} //constructor's inputs
+	Setup      func(contract *Contract) error
+
+	Actors []*ecdsa.PrivateKey
+	Probes []Probe                 //view calls compared after each step
+	Ignore func(d Difference) bool //intended changes, nothing is ignored if nil
+}
+
+//Run deploys both contracts with the same owner and executes the steps on both.
+func (p *Differential) Run(steps []Step) ([]Difference, error) {
+	older, newer := p.Old.Clone(), p.New.Clone()
+	newer.OwnerKey, newer.Owner = older.OwnerKey, older.Owner
+	defer older.Backend.Close()
+	defer newer.Backend.Close()
+
+	for _, c := range []*Contract{older, newer} {
+		if err := c.Deploy(p.DeployArgs...); err != nil {
+			return nil, err
+		}
+		if p.Setup != nil {
+			if err := p.Setup(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	diffs := []Difference{}
+	report := func(d Difference) {
+		if d.Old != d.New && (p.Ignore == nil || p.Ignore(d) == false) {
+			diffs = append(diffs, d)
+		}
+	}
+
+	p.compareState(-1, older, newer, report)
+	for i, s := range steps {
+		if s.Actor < 0 || s.Actor >= len(p.Actors) {
+			return nil, fmt.Errorf("step %d: no actor %d", i, s.Actor)
+		}
+		key := p.Actors[s.Actor]
+
+		//the return values are taken from a call before the transaction
+		oldRet, oldErr := older.callFrom(crypto.PubkeyToAddress(key.PublicKey), s.Method, s.Args...)
+		newRet, newErr := newer.callFrom(crypto.PubkeyToAddress(key.PublicKey), s.Method, s.Args...)
+		report(Difference{Step: i, Kind: "return", What: s.String(), Old: result(oldRet, oldErr), New: result(newRet, newErr)})
+
+		if m, ok := older.Abi.Methods[s.Method]; ok == false || m.Const == false {
+			oldReceipt, err := older.Execute(key, s.Method, s.Args...)
+			if err != nil {
+				return nil, fmt.Errorf("old %s: %v", s, err)
+			}
+			newReceipt, err := newer.Execute(key, s.Method, s.Args...)
+			if err != nil {
+				return nil, fmt.Errorf("new %s: %v", s, err)
+			}
+			report(Difference{Step: i, Kind: "status", What: s.String(),
+				Old: fmt.Sprint(oldReceipt.Status), New: fmt.Sprint(newReceipt.Status)})
+			report(Difference{Step: i, Kind: "event", What: s.String(),
+				Old: older.formatLogs(oldReceipt.Logs), New: newer.formatLogs(newReceipt.Logs)})
+		}
+
+		for b := uint64(0); b < s.Blocks; b++ {
+			older.Backend.Commit() //make block
+			newer.Backend.Commit()
+		}
+		p.compareState(i, older, newer, report)
+	}
+	return diffs, nil
+}
+
+//compareState reports the different results of the probes.
+func (p *Differential) compareState(step int, older, newer *Contract, report func(Difference)) {
+	for _, probe := range p.Probes {
+		oldRet, oldErr := older.LowCall(probe.Method, probe.Args...)
+		newRet, newErr := newer.LowCall(probe.Method, probe.Args...)
+		report(Difference{Step: step, Kind: "state", What: probe.String(),
+			Old: fmt.Sprint(oldRet, oldErr), New: fmt.Sprint(newRet, newErr)})
+	}
+}
+
+//callFrom invokes the method by the given sender without sending a transaction, and returns the raw output.
+func (p *Contract) callFrom(from common.Address, method string, args ...interface{}) ([]byte, error) {
+	input, err := p.pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	msg := ethereum.CallMsg{From: from, To: &p.Address, Data: input}
+	return p.Backend.CallContract(context.TODO(), msg, nil)
+}
+
+//formatLogs formats the logs of a receipt with the event names, ignoring the emitting address.
+func (p *Contract) formatLogs(logs []*types.Log) string {
+	b := strings.Builder{}
+	for _, g := range logs {
+		name := "unknown"
+		for _, e := range p.Abi.Events {
+			if len(g.Topics) > 0 && e.Id() == g.Topics[0] {
+				name = e.Name
+			}
+		}
+		fmt.Fprintf(&b, "%s%x%x;", name, g.Topics, g.Data)
+	}
+	return b.String()
+}
+
+//result formats the raw output of a call.
+func result(ret []byte, err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return fmt.Sprintf("%x", ret)
+}
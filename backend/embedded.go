@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	embeddedLock sync.Mutex
+	embedded     = map[string]map[string]*compiler.Contract{} //compiled contracts by source
+)
+
+//newEmbedded returns the named contract of a source embedded in this package, attached to the backend.
+//Each source is compiled once in a process.
+func newEmbedded(source, name string, b *Backend, key *ecdsa.PrivateKey) (*Contract, error) {
+	embeddedLock.Lock()
+	contracts, ok := embedded[source]
+	if ok == false {
+		var err error
+		if contracts, err = compiler.CompileSolidityString("", source); err != nil {
+			embeddedLock.Unlock()
+			return nil, err
+		}
+		embedded[source] = contracts
+	}
+	embeddedLock.Unlock()
+
+	r := &Contract{
+		Name:     name,
+		Backend:  b,
+		OwnerKey: key,
+		Owner:    crypto.PubkeyToAddress(key.PublicKey),
+	}
+	if err := r.setCompiled(contracts, "<stdin>"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
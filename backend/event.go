@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+//UnpackLog unpacks the event log into out, which is a pointer to a struct
+//having the fields named like the event's inputs in camel case.
+//Indexed inputs are taken from the topics, and the others from the data.
+func (p *Contract) UnpackLog(out interface{}, event string, log *types.Log) error {
+	e, ok := p.Abi.Events[event]
+	if ok == false {
+		return fmt.Errorf("%s event is not here", event)
+	}
+	if len(log.Topics) == 0 || log.Topics[0] != e.Id() {
+		return fmt.Errorf("log is not %s event", event)
+	}
+	if len(log.Data) > 0 {
+		if err := p.Abi.Unpack(out, event, log.Data); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("out is not a pointer to struct: %T", out)
+	}
+	v = v.Elem()
+
+	topics := log.Topics[1:]
+	for _, input := range e.Inputs {
+		if input.Indexed == false {
+			continue
+		}
+		if len(topics) == 0 {
+			return fmt.Errorf("%s event: topics are not enough", event)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		field := v.FieldByName(abi.ToCamelCase(input.Name))
+		if field.IsValid() == false {
+			continue
+		}
+		if err := setTopic(field, input.Type, topic); err != nil {
+			return fmt.Errorf("%s event, %s: %v", event, input.Name, err)
+		}
+	}
+	return nil
+}
+
+//LogsOf returns the logs of the receipt emitted as the event by the contract.
+func (p *Contract) LogsOf(event string, receipt *types.Receipt) []*types.Log {
+	logs := []*types.Log{}
+	e, ok := p.Abi.Events[event]
+	if ok == false {
+		return logs
+	}
+	for _, g := range receipt.Logs {
+		if g.Address == p.Address && len(g.Topics) > 0 && g.Topics[0] == e.Id() {
+			logs = append(logs, g)
+		}
+	}
+	return logs
+}
+
+//DecodeLog returns the name and the inputs by name of the event log emitted by the contract.
+func (p *Contract) DecodeLog(log *types.Log) (string, map[string]interface{}, error) {
+	if len(log.Topics) == 0 {
+		return "", nil, fmt.Errorf("anonymous log")
+	}
+	for name, e := range p.Abi.Events {
+		if e.Id() != log.Topics[0] {
+			continue
+		}
+
+		values := map[string]interface{}{}
+		if len(log.Data) > 0 {
+			if err := p.Abi.UnpackIntoMap(values, name, log.Data); err != nil {
+				return name, nil, err
+			}
+		}
+		topics := log.Topics[1:]
+		for _, input := range e.Inputs {
+			if input.Indexed == false {
+				continue
+			}
+			if len(topics) == 0 {
+				return name, nil, fmt.Errorf("%s event: topics are not enough", name)
+			}
+			v := reflect.New(input.Type.Type).Elem()
+			if hashedInTopic(input.Type) {
+				v = reflect.New(reflect.TypeOf(common.Hash{})).Elem()
+			}
+			if err := setTopic(v, input.Type, topics[0]); err != nil {
+				return name, nil, fmt.Errorf("%s event, %s: %v", name, input.Name, err)
+			}
+			values[input.Name] = v.Interface()
+			topics = topics[1:]
+		}
+		return name, values, nil
+	}
+	return "", nil, fmt.Errorf("unknown event: %s", log.Topics[0].Hex())
+}
+
+//hashedInTopic reports whether only the hash of an indexed value of the type is in the topic.
+func hashedInTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+//setTopic sets the value of the indexed input decoded from the topic.
+//Inputs of dynamic types are hashed in a topic, so they can be set only to common.Hash.
+func setTopic(field reflect.Value, t abi.Type, topic common.Hash) error {
+	if field.Type() == reflect.TypeOf(common.Hash{}) {
+		field.Set(reflect.ValueOf(topic))
+		return nil
+	}
+
+	switch t.T {
+	case abi.AddressTy:
+		field.Set(reflect.ValueOf(common.BytesToAddress(topic.Bytes())))
+		return nil
+	case abi.BoolTy:
+		field.SetBool(topic[common.HashLength-1] == 1)
+		return nil
+	case abi.IntTy, abi.UintTy:
+		n := topic.Big()
+		if t.T == abi.IntTy && n.Bit(255) == 1 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256)) //two's complement
+		}
+		if t.Size > 64 {
+			field.Set(reflect.ValueOf(n))
+		} else if t.T == abi.IntTy {
+			field.SetInt(n.Int64())
+		} else {
+			field.SetUint(n.Uint64())
+		}
+		return nil
+	case abi.FixedBytesTy:
+		reflect.Copy(field, reflect.ValueOf(topic[:t.Size]))
+		return nil
+	}
+	return fmt.Errorf("can not set %s topic to %s", t.String(), field.Type())
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//CallAs invokes a view method and returns its output as T.
+//For a method returning several values, T is a struct whose fields are named like the outputs,
+//or, if the outputs are not named, are declared in the order of the outputs.
+func CallAs[T any](contract *Contract, method string, args ...interface{}) (T, error) {
+	var out T
+
+	m, ok := contract.Abi.Methods[method]
+	if ok == false {
+		return out, fmt.Errorf("%s method is not here", method)
+	}
+	if len(m.Outputs) > 1 {
+		for _, o := range m.Outputs {
+			if o.Name == "" {
+				ret, err := contract.LowCall(method, args...)
+				if err != nil {
+					return out, err
+				}
+				return TupleAs[T](ret)
+			}
+		}
+	}
+
+	err := contract.Call(&out, method, args...)
+	return out, err
+}
+
+//ValueAs returns the i-th value of the outputs returned by LowCall as T.
+func ValueAs[T any](values []interface{}, i int) (T, error) {
+	var out T
+	if i < 0 || i >= len(values) {
+		return out, fmt.Errorf("index %d out of %d values", i, len(values))
+	}
+	v, ok := values[i].(T)
+	if ok == false {
+		return out, fmt.Errorf("value %d is %T, not %T", i, values[i], out)
+	}
+	return v, nil
+}
+
+//TupleAs sets the outputs returned by LowCall to the fields of the struct T in order.
+func TupleAs[T any](values []interface{}) (T, error) {
+	var out T
+	v := reflect.ValueOf(&out).Elem()
+	if v.Kind() != reflect.Struct {
+		return out, fmt.Errorf("%T is not a struct", out)
+	}
+	if v.NumField() != len(values) {
+		return out, fmt.Errorf("%T has %d fields for %d values", out, v.NumField(), len(values))
+	}
+	for i, value := range values {
+		field := v.Field(i)
+		if reflect.TypeOf(value).AssignableTo(field.Type()) == false {
+			return out, fmt.Errorf("value %d is %T, field %s is %s", i, value, v.Type().Field(i).Name, field.Type())
+		}
+		field.Set(reflect.ValueOf(value))
+	}
+	return out, nil
+}
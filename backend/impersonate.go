@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+//impersonatedS is the S value of the fake signature of impersonated transactions, whose R is the sender.
+var impersonatedS = big.NewInt(1)
+
+//impersonator is the signer of impersonated transactions, which gives the sender without a signature.
+//It is equal to every signer, so the sender cached in the transaction by types.Sender is taken
+//by the simulated backend and the state processor instead of recovering the fake signature.
+type impersonator struct {
+	from common.Address
+}
+
+func (p impersonator) Sender(tx *types.Transaction) (common.Address, error) {
+	return p.from, nil
+}
+
+func (p impersonator) SignatureValues(tx *types.Transaction, sig []byte) (r, s, v *big.Int, err error) {
+	return new(big.Int).SetBytes(p.from.Bytes()), new(big.Int).Set(impersonatedS), big.NewInt(27), nil
+}
+
+func (p impersonator) Hash(tx *types.Transaction) common.Hash {
+	return types.HomesteadSigner{}.Hash(tx)
+}
+
+func (p impersonator) Equal(types.Signer) bool {
+	return true
+}
+
+//impersonate returns the transaction with the fake signature of the sender, which has the sender cached.
+func impersonate(tx *types.Transaction, from common.Address) (*types.Transaction, error) {
+	tx, err := tx.WithSignature(impersonator{from}, make([]byte, 65))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := types.Sender(impersonator{from}, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+//Impersonated returns the sender of a transaction sent by ExecuteAs, which has a fake signature.
+func Impersonated(tx *types.Transaction) (common.Address, bool) {
+	v, r, s := tx.RawSignatureValues()
+	if v.Cmp(big.NewInt(27)) != 0 || s.Cmp(impersonatedS) != 0 || r.BitLen() > 8*common.AddressLength {
+		return common.Address{}, false
+	}
+	return common.BigToAddress(r), true
+}
+
+//Sender returns the sender of the transaction, which may be impersonated.
+func Sender(signer types.Signer, tx *types.Transaction) (common.Address, error) {
+	if from, ok := Impersonated(tx); ok == true {
+		return from, nil
+	}
+	return types.Sender(signer, tx)
+}
@@ -0,0 +1,317 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Step is one action of a call sequence.
+//The actor sends a transaction to the method, and then Blocks empty blocks are made.
+type Step struct {
+	Actor  int
+	Method string
+	Args   []interface{}
+	Blocks uint64
+}
+
+func (p Step) String() string {
+	args := make([]string, len(p.Args))
+	for i, a := range p.Args {
+		args[i] = fmt.Sprint(a)
+	}
+	return fmt.Sprintf("actor[%d] %s(%s) +%d blocks", p.Actor, p.Method, strings.Join(args, ", "), p.Blocks)
+}
+
+//Invariant is a property that must hold after every step of a call sequence.
+//Check returns an error describing the violation.
+type Invariant struct {
+	Name  string
+	Check func(contract *Contract) error
+}
+
+//ArgsFunc makes the arguments of a method for one step.
+type ArgsFunc func(r *rand.Rand, actors []common.Address) []interface{}
+
+//InvariantTest executes random call sequences from a pool of actors,
+//and checks the invariants after each step.
+type InvariantTest struct {
+	Contract   *Contract     //compiled contract, deployed again on a new backend for each sequence
+	DeployArgs []interface{} //constructor's inputs
+	Setup      func(contract *Contract) error
+
+	Actors     []*ecdsa.PrivateKey
+	Methods    []string            //methods to call, all non-constant methods if empty
+	Args       map[string]ArgsFunc //argument makers by method, random arguments by ABI type if not set
+	Invariants []Invariant
+
+	Runs      int    //number of sequences
+	Depth     int    //number of steps of a sequence
+	MaxBlocks uint64 //maximum number of blocks to make after a step
+	Seed      int64
+}
+
+//InvariantFailure holds a violated invariant and the shrunk sequence which reproduces it.
+type InvariantFailure struct {
+	Invariant string
+	Err       error
+	Seed      int64
+	Run       int
+	Steps     []Step
+}
+
+func (p *InvariantFailure) Error() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "invariant %q violated: %v (seed: %d, run: %d)", p.Invariant, p.Err, p.Seed, p.Run)
+	for i, s := range p.Steps {
+		fmt.Fprintf(&b, "\n  %d: %s", i, s)
+	}
+	return b.String()
+}
+
+//Run executes the sequences and returns the first failure after shrinking it.
+//The error is returned only when a sequence can not be executed.
+func (p *InvariantTest) Run() (*InvariantFailure, error) {
+	if len(p.Actors) == 0 {
+		return nil, fmt.Errorf("no actors")
+	}
+	methods := p.Methods
+	if len(methods) == 0 {
+		for name, m := range p.Contract.Abi.Methods {
+			if m.Const == false {
+				methods = append(methods, name)
+			}
+		}
+		sort.Strings(methods) //the same seed makes the same sequences
+	}
+	for _, name := range methods {
+		if _, ok := p.Contract.Abi.Methods[name]; ok == false {
+			return nil, fmt.Errorf("%s method is not here", name)
+		}
+	}
+
+	actors := make([]common.Address, len(p.Actors))
+	for i, key := range p.Actors {
+		actors[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+
+	r := rand.New(rand.NewSource(p.Seed))
+	for run := 0; run < p.Runs; run++ {
+		contract, err := p.deploy()
+		if err != nil {
+			return nil, err
+		}
+
+		steps := []Step{}
+		name, violation := p.check(contract)
+		for i := 0; err == nil && violation == nil && i < p.Depth; i++ {
+			method := methods[r.Intn(len(methods))]
+			step := Step{Actor: r.Intn(len(p.Actors)), Method: method}
+			if f, ok := p.Args[method]; ok == true {
+				step.Args = f(r, actors)
+			} else if step.Args, err = RandomArgs(r, p.Contract.Abi.Methods[method].Inputs, actors); err != nil {
+				break
+			}
+			if p.MaxBlocks > 0 {
+				step.Blocks = uint64(r.Int63n(int64(p.MaxBlocks) + 1))
+			}
+			steps = append(steps, step)
+
+			if err = p.step(contract, step); err == nil {
+				name, violation = p.check(contract)
+			}
+		}
+		contract.Backend.Close()
+
+		if err != nil {
+			return nil, err
+		}
+		if violation != nil {
+			failure := &InvariantFailure{Invariant: name, Seed: p.Seed, Run: run}
+			failure.Steps, failure.Err = p.shrink(steps, name, violation)
+			return failure, nil
+		}
+	}
+	return nil, nil
+}
+
+//deploy makes a new instance of the contract on a new backend and sets it up.
+func (p *InvariantTest) deploy() (*Contract, error) {
+	contract := p.Contract.Clone()
+	if err := contract.Deploy(p.DeployArgs...); err != nil {
+		return nil, err
+	}
+	if p.Setup != nil {
+		if err := p.Setup(contract); err != nil {
+			return nil, err
+		}
+	}
+	return contract, nil
+}
+
+//step executes a step. A reverted transaction is not an error.
+func (p *InvariantTest) step(contract *Contract, s Step) error {
+	if _, err := contract.Execute(p.Actors[s.Actor], s.Method, s.Args...); err != nil {
+		return fmt.Errorf("%s: %v", s, err)
+	}
+	for b := uint64(0); b < s.Blocks; b++ {
+		contract.Backend.Commit() //make block
+	}
+	return nil
+}
+
+//check returns the name and the error of the first violated invariant.
+func (p *InvariantTest) check(contract *Contract) (string, error) {
+	for _, inv := range p.Invariants {
+		if violation := inv.Check(contract); violation != nil {
+			return inv.Name, violation
+		}
+	}
+	return "", nil
+}
+
+//replay executes the steps on a new instance, and returns the violation of the named invariant if it occurs.
+func (p *InvariantTest) replay(steps []Step, name string) (violation error, err error) {
+	contract, err := p.deploy()
+	if err != nil {
+		return nil, err
+	}
+	defer contract.Backend.Close()
+
+	for i := -1; i < len(steps); i++ {
+		if i >= 0 {
+			if err := p.step(contract, steps[i]); err != nil {
+				return nil, err
+			}
+		}
+		if got, violation := p.check(contract); violation != nil && got == name {
+			return violation, nil
+		}
+	}
+	return nil, nil
+}
+
+//shrink removes steps and blocks that are not needed to violate the named invariant.
+func (p *InvariantTest) shrink(steps []Step, name string, violation error) ([]Step, error) {
+	for i := 0; i < len(steps); {
+		candidate := append(append([]Step{}, steps[:i]...), steps[i+1:]...)
+		if v, err := p.replay(candidate, name); err == nil && v != nil {
+			steps, violation = candidate, v
+		} else {
+			i++
+		}
+	}
+
+	for i := range steps {
+		for steps[i].Blocks > 0 {
+			candidate := append([]Step{}, steps...)
+			candidate[i].Blocks /= 2
+			if v, err := p.replay(candidate, name); err == nil && v != nil {
+				steps, violation = candidate, v
+			} else {
+				break
+			}
+		}
+	}
+	return steps, violation
+}
+
+//RandomArgs makes random values of the given ABI arguments.
+//Addresses are chosen among the actors.
+func RandomArgs(r *rand.Rand, inputs abi.Arguments, actors []common.Address) ([]interface{}, error) {
+	args := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		v, err := randomValue(r, input.Type, actors)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", input.Name, err)
+		}
+		args[i] = v.Interface()
+	}
+	return args, nil
+}
+
+//randomValue makes a random value of the given ABI type, biased to boundary values.
+func randomValue(r *rand.Rand, t abi.Type, actors []common.Address) (reflect.Value, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		bits := uint(t.Size)
+		if t.T == abi.IntTy {
+			bits--
+		}
+		max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+
+		n := new(big.Int)
+		switch r.Intn(4) {
+		case 0:
+			n.SetInt64(r.Int63n(3))
+		case 1:
+			n.Set(max)
+		case 2:
+			n.SetInt64(r.Int63n(1000))
+		default:
+			n.Rand(r, max)
+		}
+		if t.T == abi.IntTy && r.Intn(2) == 0 {
+			n.Neg(n)
+		}
+
+		if t.Size > 64 {
+			return reflect.ValueOf(n), nil
+		}
+		v := reflect.New(t.Type).Elem()
+		if t.T == abi.IntTy {
+			v.SetInt(n.Int64())
+		} else {
+			v.SetUint(n.Uint64())
+		}
+		return v, nil
+	case abi.BoolTy:
+		return reflect.ValueOf(r.Intn(2) == 0), nil
+	case abi.AddressTy:
+		if len(actors) == 0 || r.Intn(10) == 0 {
+			return reflect.ValueOf(common.Address{}), nil
+		}
+		return reflect.ValueOf(actors[r.Intn(len(actors))]), nil
+	case abi.StringTy:
+		b := make([]byte, r.Intn(16))
+		for i := range b {
+			b[i] = byte('a' + r.Intn(26))
+		}
+		return reflect.ValueOf(string(b)), nil
+	case abi.BytesTy:
+		b := make([]byte, r.Intn(64))
+		r.Read(b)
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy:
+		v := reflect.New(t.Type).Elem()
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).SetUint(uint64(r.Intn(256)))
+		}
+		return v, nil
+	case abi.SliceTy, abi.ArrayTy:
+		v := reflect.Value{}
+		if t.T == abi.SliceTy {
+			n := r.Intn(4)
+			v = reflect.MakeSlice(t.Type, n, n)
+		} else {
+			v = reflect.New(t.Type).Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			e, err := randomValue(r, *t.Elem, actors)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(e)
+		}
+		return v, nil
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported type: %s", t.String())
+}
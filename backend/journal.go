@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+//Journal holds the transactions of every block of a simulated blockchain,
+//so that the same blockchain can be made again on a new backend.
+type Journal struct {
+	Blocks []JournalBlock `json:"blocks"`
+}
+
+//JournalBlock holds the RLP encoded transactions of a block, or the cheats which made the block, with its timestamp.
+type JournalBlock struct {
+	Time   uint64          `json:"time,omitempty"` //not recorded if 0, as journals written before it was recorded
+	Txs    []hexutil.Bytes `json:"txs,omitempty"`
+	Cheats []Cheat         `json:"cheats,omitempty"`
+}
+
+//RecordJournal reads the transactions of all blocks after the genesis from the backend.
+func RecordJournal(b *Backend) (*Journal, error) {
+	r := &Journal{}
+	head := b.Blockchain().CurrentBlock().NumberU64()
+	for n := uint64(1); n <= head; n++ {
+		block := b.Blockchain().GetBlockByNumber(n)
+		if block == nil {
+			return nil, fmt.Errorf("block %d is not here", n)
+		}
+		jb := JournalBlock{Time: block.Time(), Cheats: b.cheatsOf(block.Hash())}
+		for _, tx := range block.Transactions() {
+			raw, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				return nil, err
+			}
+			jb.Txs = append(jb.Txs, raw)
+		}
+		r.Blocks = append(r.Blocks, jb)
+	}
+	return r, nil
+}
+
+//Replay sends the transactions to the backend, or applies the cheats, and makes the blocks in order.
+//The time of a block of transactions is moved by AdjustTime to the recorded one before the transactions are sent,
+//as evm_increaseTime does, so the blocks are made again with the same timestamps and hashes.
+//The backend is expected to be new.
+func (p *Journal) Replay(b *Backend) error {
+	for n, jb := range p.Blocks {
+		if err := p.replay(b, jb); err != nil {
+			return fmt.Errorf("block %d: %v", n+1, err)
+		}
+		if head := b.Blockchain().CurrentBlock(); jb.Time != 0 && head.Time() != jb.Time {
+			return fmt.Errorf("block %d is made at %d, not at the recorded time %d", n+1, head.Time(), jb.Time)
+		}
+	}
+	return nil
+}
+
+//replay makes the block of the journal on the head of the backend.
+func (p *Journal) replay(b *Backend, jb JournalBlock) error {
+	if len(jb.Cheats) > 0 {
+		return applyCheats(b, jb.Cheats...)
+	}
+
+	if jb.Time != 0 {
+		//a new block is made 10 seconds after its parent
+		offset := int64(jb.Time) - int64(b.Blockchain().CurrentBlock().Time()+10)
+		if offset != 0 {
+			if err := b.AdjustTime(time.Duration(offset) * time.Second); err != nil {
+				return err
+			}
+		}
+	}
+	for i, raw := range jb.Txs {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(raw, tx); err != nil {
+			return fmt.Errorf("tx %d: %v", i, err)
+		}
+		if from, ok := Impersonated(tx); ok == true {
+			impersonated, err := impersonate(tx, from)
+			if err != nil {
+				return fmt.Errorf("tx %d: %v", i, err)
+			}
+			tx = impersonated
+		}
+		if err := b.SendTransaction(context.Background(), tx); err != nil {
+			return fmt.Errorf("tx %d: %v", i, err)
+		}
+	}
+	b.Commit() //make block
+	return nil
+}
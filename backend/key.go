@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//SeedEnv is the environment variable holding the seed of the derived keys.
+//A run is reproduced by setting it to the seed printed by the failed run.
+const SeedEnv = "CONTRACT_TEST_SEED"
+
+var (
+	seedLock sync.Mutex
+	seed     string
+)
+
+//Seed returns the seed of the derived keys, which is taken from SeedEnv, or made randomly once per process.
+func Seed() string {
+	seedLock.Lock()
+	defer seedLock.Unlock()
+	if seed == "" {
+		seed = os.Getenv(SeedEnv)
+	}
+	if seed == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			panic(fmt.Errorf("seed: %v", err))
+		}
+		seed = hexutil.Encode(b)
+	}
+	return seed
+}
+
+//SetSeed sets the seed of the keys derived after it.
+func SetSeed(s string) {
+	seedLock.Lock()
+	defer seedLock.Unlock()
+	seed = s
+}
+
+//DeriveKey derives the private key of the name from the seed.
+//The same seed and name always give the same key.
+func DeriveKey(seed, name string) *ecdsa.PrivateKey {
+	for i := uint64(0); ; i++ {
+		counter := make([]byte, 8)
+		binary.BigEndian.PutUint64(counter, i)
+		//a hash is not a valid key only if it is zero or not less than the curve order
+		if key, err := crypto.ToECDSA(crypto.Keccak256([]byte(seed), []byte{0}, []byte(name), counter)); err == nil {
+			return key
+		}
+	}
+}
+
+//KeyRing derives the keys of a name in order, so that the n-th key is the same for the same seed.
+type KeyRing struct {
+	mu   sync.Mutex
+	seed string
+	name string
+	n    int
+}
+
+//NewKeyRing returns a key ring of the name, such as a test name, with the current Seed.
+func NewKeyRing(name string) *KeyRing {
+	return &KeyRing{seed: Seed(), name: name}
+}
+
+//Seed returns the seed of the key ring.
+func (p *KeyRing) Seed() string {
+	return p.seed
+}
+
+//Next returns the next key of the key ring.
+func (p *KeyRing) Next() *ecdsa.PrivateKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n++
+	return DeriveKey(p.seed, fmt.Sprintf("%s#%d", p.name, p.n))
+}
+
+//Key returns the key of the account name in the key ring, which does not depend on the order.
+func (p *KeyRing) Key(account string) *ecdsa.PrivateKey {
+	return DeriveKey(p.seed, p.name+"/"+account)
+}
@@ -0,0 +1,276 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//StorageVariable is a state variable in the storage layout given by solc.
+type StorageVariable struct {
+	Label    string //variable name
+	Contract string //contract declaring the variable
+	Slot     uint64
+	Offset   int    //bytes from the right of the slot
+	Type     string //type name without AST ids, like "t_struct(Partner)_storage"
+	TypeName string //like "struct WemixToken.Partner[]"
+	Bytes    int    //bytes occupied in place, 32 for mappings and dynamic arrays
+}
+
+//String returns the type and the name of the variable with its place, like "uint256 Counter.count at slot 2 offset 0".
+func (p StorageVariable) String() string {
+	return fmt.Sprintf("%s %s.%s at slot %d offset %d", p.TypeName, p.Contract, p.Label, p.Slot, p.Offset)
+}
+
+//end returns the byte position after the variable in the storage.
+func (p StorageVariable) end() uint64 {
+	return p.Slot*32 + uint64(p.Offset+p.Bytes)
+}
+
+//StorageLayout is the state variables of a contract in the order of the storage.
+type StorageLayout []StorageVariable
+
+//Find returns the variable of the label declared in the contract.
+func (p StorageLayout) Find(contract, label string) (StorageVariable, bool) {
+	for _, v := range p {
+		if v.Contract == contract && v.Label == label {
+			return v, true
+		}
+	}
+	return StorageVariable{}, false
+}
+
+//Lookup returns the variable by its label, or by "Contract.label" if contracts in the inheritance chain
+//declare variables of the same label.
+func (p StorageLayout) Lookup(name string) (StorageVariable, error) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		v, ok := p.Find(name[:i], name[i+1:])
+		if ok == false {
+			return StorageVariable{}, fmt.Errorf("%s variable is not here", name)
+		}
+		return v, nil
+	}
+	found := []StorageVariable{}
+	for _, v := range p {
+		if v.Label == name {
+			found = append(found, v)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return StorageVariable{}, fmt.Errorf("%s variable is not here", name)
+	case 1:
+		return found[0], nil
+	}
+	contracts := []string{}
+	for _, v := range found {
+		contracts = append(contracts, v.Contract)
+	}
+	return StorageVariable{}, fmt.Errorf("%s variable is declared in %s, give it as Contract.%s", name, strings.Join(contracts, ", "), name)
+}
+
+//count returns the number of variables of the label.
+func (p StorageLayout) count(label string) int {
+	n := 0
+	for _, v := range p {
+		if v.Label == label {
+			n++
+		}
+	}
+	return n
+}
+
+//match returns the variable of p matching v of the other layout: the one of the same contract and label,
+//or the only one of the label if the label is unique in both layouts, as the contract may be renamed between versions.
+func (p StorageLayout) match(other StorageLayout, v *StorageVariable) (StorageVariable, bool) {
+	if r, ok := p.Find(v.Contract, v.Label); ok == true {
+		return r, true
+	}
+	if p.count(v.Label) != 1 || other.count(v.Label) != 1 {
+		return StorageVariable{}, false
+	}
+	for _, r := range p {
+		if r.Label == v.Label {
+			return r, true
+		}
+	}
+	return StorageVariable{}, false
+}
+
+//astId matches the AST ids after the names of structs, enums and contracts in type identifiers,
+//which change with the source.
+var astId = regexp.MustCompile(`(t_(?:struct|enum|contract)\([^)]*\))\d+`)
+
+//StorageLayout compiles the source file of the contract with solc to get its storage layout.
+func (p *Contract) StorageLayout() (StorageLayout, error) {
+	if p.File == "" {
+		return nil, fmt.Errorf("%s contract has no source file", p.Name)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("solc", "--combined-json", "storage-layout", p.File)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("solc: %v\n%s", err, stderr.String())
+	}
+
+	combined := struct {
+		Contracts map[string]struct {
+			StorageLayout json.RawMessage `json:"storage-layout"`
+		} `json:"contracts"`
+	}{}
+	if err := json.Unmarshal(out, &combined); err != nil {
+		return nil, err
+	}
+	c, ok := combined.Contracts[fmt.Sprintf("%s:%s", p.File, p.Name)]
+	if ok == false {
+		return nil, fmt.Errorf("%s contract is not here", p.Name)
+	}
+	raw := []byte(c.StorageLayout)
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		raw = []byte(s) //older solc gives the layout as a JSON string
+	}
+	return parseLayout(raw)
+}
+
+//parseLayout parses the storage layout JSON of solc.
+func parseLayout(raw []byte) (StorageLayout, error) {
+	layout := struct {
+		Storage []struct {
+			Label    string `json:"label"`
+			Contract string `json:"contract"`
+			Slot     string `json:"slot"`
+			Offset   int    `json:"offset"`
+			Type     string `json:"type"`
+		} `json:"storage"`
+		Types map[string]struct {
+			Label         string `json:"label"`
+			NumberOfBytes string `json:"numberOfBytes"`
+		} `json:"types"`
+	}{}
+	if err := json.Unmarshal(raw, &layout); err != nil {
+		return nil, fmt.Errorf("storage layout: %v", err)
+	}
+
+	r := StorageLayout{}
+	for _, s := range layout.Storage {
+		slot, err := strconv.ParseUint(s.Slot, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: slot %q: %v", s.Label, s.Slot, err)
+		}
+		t := layout.Types[s.Type]
+		size, err := strconv.Atoi(t.NumberOfBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: size %q: %v", s.Label, t.NumberOfBytes, err)
+		}
+		contract := s.Contract
+		if i := strings.LastIndex(contract, ":"); i >= 0 {
+			contract = contract[i+1:]
+		}
+		r = append(r, StorageVariable{
+			Label:    s.Label,
+			Contract: contract,
+			Slot:     slot,
+			Offset:   s.Offset,
+			Type:     astId.ReplaceAllString(s.Type, "$1"),
+			TypeName: t.Label,
+			Bytes:    size,
+		})
+	}
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Slot < r[j].Slot || (r[i].Slot == r[j].Slot && r[i].Offset < r[j].Offset)
+	})
+	return r, nil
+}
+
+//LayoutChange is a change of a state variable between two storage layouts.
+type LayoutChange struct {
+	Kind  string //moved, retyped, removed, inserted or appended
+	Label string
+	Old   *StorageVariable //nil if inserted or appended
+	New   *StorageVariable //nil if removed
+}
+
+//Safe reports whether the change keeps the storage of the old layout, which is only appending.
+func (p LayoutChange) Safe() bool {
+	return p.Kind == "appended"
+}
+
+//String returns the kind of the change with the new and the old variables.
+func (p LayoutChange) String() string {
+	switch {
+	case p.Old == nil:
+		return fmt.Sprintf("%s: %s", p.Kind, p.New)
+	case p.New == nil:
+		return fmt.Sprintf("%s: %s", p.Kind, p.Old)
+	}
+	return fmt.Sprintf("%s: %s, was %s", p.Kind, p.New, p.Old)
+}
+
+//CompareLayout returns the changes from the old storage layout to the new one.
+//Variables are matched by the contract declaring them and their label, or by label only if it is unique in both layouts.
+//A new variable is appended if it is placed after all the old variables, and inserted otherwise.
+func CompareLayout(older, newer StorageLayout) []LayoutChange {
+	changes := []LayoutChange{}
+	end := uint64(0)
+	for i := range older {
+		o := &older[i]
+		if o.end() > end {
+			end = o.end()
+		}
+		v, ok := newer.match(older, o)
+		if ok == false {
+			changes = append(changes, LayoutChange{Kind: "removed", Label: o.Label, Old: o})
+			continue
+		}
+		n := &v
+		if n.Slot != o.Slot || n.Offset != o.Offset {
+			changes = append(changes, LayoutChange{Kind: "moved", Label: o.Label, Old: o, New: n})
+		}
+		if n.Type != o.Type || n.Bytes != o.Bytes {
+			changes = append(changes, LayoutChange{Kind: "retyped", Label: o.Label, Old: o, New: n})
+		}
+	}
+
+	for i := range newer {
+		n := &newer[i]
+		if _, ok := older.match(newer, n); ok == true {
+			continue
+		}
+		kind := "inserted"
+		if n.Slot*32+uint64(n.Offset) >= end {
+			kind = "appended"
+		}
+		changes = append(changes, LayoutChange{Kind: kind, Label: n.Label, New: n})
+	}
+	return changes
+}
+
+//CheckUpgrade compares the storage layouts of two versions of a contract,
+//and returns an error listing the changes which are not safe for an upgrade behind a proxy.
+func CheckUpgrade(older, newer *Contract) error {
+	oldLayout, err := older.StorageLayout()
+	if err != nil {
+		return err
+	}
+	newLayout, err := newer.StorageLayout()
+	if err != nil {
+		return err
+	}
+	unsafe := []string{}
+	for _, c := range CompareLayout(oldLayout, newLayout) {
+		if c.Safe() == false {
+			unsafe = append(unsafe, c.String())
+		}
+	}
+	if len(unsafe) > 0 {
+		return fmt.Errorf("unsafe storage layout changes from %s to %s:\n\t%s", older.Name, newer.Name, strings.Join(unsafe, "\n\t"))
+	}
+	return nil
+}
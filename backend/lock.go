@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+//Backend is a simulated backend with the lock of its transactions,
+//and the record of the blocks made by cheatcodes.
+type Backend struct {
+	*backends.SimulatedBackend
+	mu sync.Mutex //held by LockBackend
+
+	recordLock sync.Mutex
+	pending    int                     //transactions sent since the last block
+	cheats     map[common.Hash][]Cheat //by the hash of the block made by the cheats
+}
+
+//WrapBackend returns the simulated backend with a new lock.
+//The simulated backend should only be used through the returned Backend.
+func WrapBackend(b *backends.SimulatedBackend) *Backend {
+	return &Backend{SimulatedBackend: b}
+}
+
+//LockBackend locks the backend for sending transactions and making blocks, and returns the unlock function.
+//Deploy and Execute hold the lock from taking the pending nonce to reading the receipt,
+//so each of their transactions is mined alone in its own block even if they run in several goroutines.
+//Code committing blocks by itself while others send transactions should hold the lock too.
+func LockBackend(b *Backend) func() {
+	b.mu.Lock()
+	return b.mu.Unlock
+}
+
+//SendTransaction adds the transaction to the pending block.
+func (p *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := p.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	p.recordLock.Lock()
+	p.pending++
+	p.recordLock.Unlock()
+	return nil
+}
+
+//Commit makes a block of the pending transactions.
+func (p *Backend) Commit() {
+	p.SimulatedBackend.Commit()
+	p.recordLock.Lock()
+	p.pending = 0
+	p.recordLock.Unlock()
+}
+
+//Rollback drops the pending transactions.
+func (p *Backend) Rollback() {
+	p.SimulatedBackend.Rollback()
+	p.recordLock.Lock()
+	p.pending = 0
+	p.recordLock.Unlock()
+}
+
+//Close waits for the transaction holding the lock, and closes the backend.
+func (p *Backend) Close() error {
+	defer LockBackend(p)()
+	return p.SimulatedBackend.Close()
+}
@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//mockSource is the contract deployed by DeployMock.
+//Its fallback answers each selector with the response set by mockRespond, and records the call with a call to itself,
+//which fails in a static call, so calls to view methods are answered but not recorded.
+const mockSource = `pragma solidity >= 0.6.0 <0.7.0;
+
+contract Mock {
+    struct Response {
+        bool set;
+        bool reverts;
+        bytes data;
+    }
+
+    struct Call {
+        address from;
+        uint256 value;
+        bytes data;
+    }
+
+    mapping(bytes4 => Response) private responses;
+    Call[] private calls;
+
+    function mockRespond(bytes4 selector, bool reverts, bytes memory data) public {
+        responses[selector] = Response(true, reverts, data);
+    }
+
+    function mockCallCount() public view returns (uint256) {
+        return calls.length;
+    }
+
+    function mockCall(uint256 i) public view returns (address from, uint256 value, bytes memory data) {
+        Call storage c = calls[i];
+        return (c.from, c.value, c.data);
+    }
+
+    function mockReset() public {
+        delete calls;
+    }
+
+    function mockRecord(address from, uint256 value, bytes memory data) public {
+        require(msg.sender == address(this), "Mock: only the mock records");
+        calls.push(Call(from, value, data));
+    }
+
+    fallback() external payable {
+        (bool recorded, ) = address(this).call{gas: gasleft() / 2}(
+            abi.encodeWithSelector(this.mockRecord.selector, msg.sender, msg.value, msg.data));
+        recorded;
+
+        Response storage r = responses[msg.sig];
+        require(r.set, "Mock: no response for the selector");
+        bytes memory data = r.data;
+        if (r.reverts) {
+            assembly { revert(add(data, 32), mload(data)) }
+        }
+        assembly { return(add(data, 32), mload(data)) }
+    }
+}
+`
+
+//Mock is a stand-in contract with the ABI of another contract.
+//Its methods answer what is set by Returns and Reverts, and the calls it receives are kept for Calls.
+//Methods not set revert with "Mock: no response for the selector".
+//The mocked ABI should not have the methods of the mock itself, whose names start with "mock".
+type Mock struct {
+	*Contract           //mocked ABI bound to the address of the mock
+	mock      *Contract //mock contract with its own ABI
+}
+
+//MockCall is a call received by a mock.
+type MockCall struct {
+	From   common.Address
+	Value  *big.Int
+	Method string        //empty if the selector is not in the mocked ABI
+	Args   []interface{} //inputs of the method
+	Data   []byte
+}
+
+//DeployMock deploys a mock of the ABI on the backend by the key.
+func DeployMock(b *Backend, definition *abi.ABI, key *ecdsa.PrivateKey) (*Mock, error) {
+	mock, err := newEmbedded(mockSource, "Mock", b, key)
+	if err != nil {
+		return nil, fmt.Errorf("compile mock: %v", err)
+	}
+	if err := mock.Deploy(); err != nil {
+		return nil, fmt.Errorf("deploy mock: %v", err)
+	}
+
+	c := *mock
+	c.Abi = definition
+	c.Info = nil
+	return &Mock{Contract: &c, mock: mock}, nil
+}
+
+//NewMock deploys a mock of the contract's ABI on its backend by its owner.
+//The contract does not need to be deployed.
+func NewMock(target *Contract) (*Mock, error) {
+	r, err := DeployMock(target.Backend, target.Abi, target.OwnerKey)
+	if err != nil {
+		return nil, err
+	}
+	r.Name = target.Name
+	return r, nil
+}
+
+//Returns sets the values returned by the method.
+func (p *Mock) Returns(method string, values ...interface{}) error {
+	m, ok := p.Abi.Methods[method]
+	if ok == false {
+		return fmt.Errorf("%s method is not in the mocked ABI", method)
+	}
+	data, err := m.Outputs.Pack(values...)
+	if err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return p.respond(m, false, data)
+}
+
+//Reverts makes the method revert with the reason.
+func (p *Mock) Reverts(method, reason string) error {
+	m, ok := p.Abi.Methods[method]
+	if ok == false {
+		return fmt.Errorf("%s method is not in the mocked ABI", method)
+	}
+	return p.respond(m, true, packRevert(reason))
+}
+
+//respond sets the response of the method to the mock.
+func (p *Mock) respond(m abi.Method, reverts bool, data []byte) error {
+	var selector [4]byte
+	copy(selector[:], m.Id())
+	receipt, err := p.mock.Execute(nil, "mockRespond", selector, reverts, data)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != 1 {
+		return fmt.Errorf("status of mockRespond tx receipt: %v", receipt.Status)
+	}
+	return nil
+}
+
+//Calls returns the calls of the method received by the mock in order, or all the calls if method is empty.
+func (p *Mock) Calls(method string) ([]MockCall, error) {
+	ret, err := p.mock.LowCall("mockCallCount")
+	if err != nil {
+		return nil, err
+	}
+	count := ret[0].(*big.Int).Int64()
+
+	calls := []MockCall{}
+	for i := int64(0); i < count; i++ {
+		ret, err := p.mock.LowCall("mockCall", big.NewInt(i))
+		if err != nil {
+			return nil, err
+		}
+		c := MockCall{From: ret[0].(common.Address), Value: ret[1].(*big.Int), Data: ret[2].([]byte)}
+		if len(c.Data) >= 4 {
+			if m, err := p.Abi.MethodById(c.Data[:4]); err == nil {
+				c.Method = m.Name
+				if c.Args, err = m.Inputs.UnpackValues(c.Data[4:]); err != nil {
+					return nil, fmt.Errorf("%s call %d: %v", m.Name, i, err)
+				}
+			}
+		}
+		if method == "" || c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls, nil
+}
+
+//Reset forgets the calls received by the mock. The responses are kept.
+func (p *Mock) Reset() error {
+	receipt, err := p.mock.Execute(nil, "mockReset")
+	if err != nil {
+		return err
+	}
+	if receipt.Status != 1 {
+		return fmt.Errorf("status of mockReset tx receipt: %v", receipt.Status)
+	}
+	return nil
+}
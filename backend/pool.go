@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+)
+
+//Pool builds a fixture chain once, by deploying the contract and running Setup on it,
+//and gives each test an isolated copy of the chain on its own backend.
+//Get can be called from parallel tests.
+type Pool struct {
+	Contract   *Contract //compiled contract, deployed on a new backend when the fixture is built
+	DeployArgs []interface{}
+	Setup      func(c *Contract) error //optional, run on the deployed contract of the fixture
+
+	once    sync.Once
+	err     error
+	journal *Journal
+	fixture *Contract
+}
+
+//NewPool compiles the contract of the pool.
+func NewPool(file, name string, setup func(c *Contract) error, deployArgs ...interface{}) (*Pool, error) {
+	contract, err := Compile(file, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{Contract: contract, DeployArgs: deployArgs, Setup: setup}, nil
+}
+
+//build deploys the contract and runs Setup on a new backend, and records its journal.
+func (p *Pool) build() {
+	contract := p.Contract.Clone()
+	defer contract.Backend.Close()
+
+	if p.err = contract.Deploy(p.DeployArgs...); p.err != nil {
+		return
+	}
+	if p.Setup != nil {
+		if p.err = p.Setup(contract); p.err != nil {
+			return
+		}
+	}
+	if p.journal, p.err = RecordJournal(contract.Backend); p.err != nil {
+		return
+	}
+	p.fixture = contract
+}
+
+//Get returns a copy of the fixture contract on a new backend holding the fixture chain,
+//and the function closing the backend.
+//The fixture is built by the first call.
+func (p *Pool) Get() (*Contract, func(), error) {
+	p.once.Do(p.build)
+	if p.err != nil {
+		return nil, nil, fmt.Errorf("fixture of %s: %v", p.Contract.Name, p.err)
+	}
+
+	b := NewBackend()
+	if err := p.journal.Replay(b); err != nil {
+		b.Close()
+		return nil, nil, fmt.Errorf("fixture of %s: %v", p.Contract.Name, err)
+	}
+	r := *p.fixture
+	r.Backend = b
+	return &r, func() { b.Close() }, nil
+}
@@ -0,0 +1,227 @@
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//proxySource is the transparent proxy deployed by DeployProxy.
+//It keeps the implementation and the admin in the EIP-1967 slots, and delegates every call to the implementation
+//but upgradeTo and upgradeToAndCall sent by the admin.
+const proxySource = `pragma solidity >= 0.6.0 <0.7.0;
+
+contract TransparentProxy {
+    //bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+    bytes32 private constant IMPLEMENTATION_SLOT = 0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc;
+    //bytes32(uint256(keccak256("eip1967.proxy.admin")) - 1)
+    bytes32 private constant ADMIN_SLOT = 0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103;
+
+    event Upgraded(address indexed implementation);
+
+    constructor(address _implementation, address _admin, bytes memory _data) public payable {
+        bytes32 slot = ADMIN_SLOT;
+        assembly { sstore(slot, _admin) }
+        _upgradeToAndCall(_implementation, _data);
+    }
+
+    function _admin() private view returns (address admin) {
+        bytes32 slot = ADMIN_SLOT;
+        assembly { admin := sload(slot) }
+    }
+
+    function _upgradeToAndCall(address _implementation, bytes memory _data) private {
+        uint256 size;
+        assembly { size := extcodesize(_implementation) }
+        require(size > 0, "TransparentProxy: implementation is not a contract");
+
+        bytes32 slot = IMPLEMENTATION_SLOT;
+        assembly { sstore(slot, _implementation) }
+        emit Upgraded(_implementation);
+
+        if (_data.length > 0) {
+            (bool ok, bytes memory ret) = _implementation.delegatecall(_data);
+            if (!ok) {
+                assembly { revert(add(ret, 32), mload(ret)) }
+            }
+        }
+    }
+
+    fallback() external payable {
+        if (msg.sender == _admin()) {
+            if (msg.sig == bytes4(keccak256("upgradeTo(address)"))) {
+                (address implementation) = abi.decode(msg.data[4:], (address));
+                _upgradeToAndCall(implementation, "");
+                return;
+            }
+            if (msg.sig == bytes4(keccak256("upgradeToAndCall(address,bytes)"))) {
+                (address implementation, bytes memory data) = abi.decode(msg.data[4:], (address, bytes));
+                _upgradeToAndCall(implementation, data);
+                return;
+            }
+        }
+        assembly {
+            let implementation := sload(0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc)
+            calldatacopy(0, 0, calldatasize())
+            let ok := delegatecall(gas(), implementation, 0, calldatasize(), 0, 0)
+            returndatacopy(0, 0, returndatasize())
+            switch ok
+            case 0 { revert(0, returndatasize()) }
+            default { return(0, returndatasize()) }
+        }
+    }
+}
+`
+
+//proxyAdminAbi is the ABI of the methods handled by the proxy itself for the admin.
+const proxyAdminAbi = `[
+	{"type":"function","name":"upgradeTo","inputs":[{"name":"implementation","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"upgradeToAndCall","inputs":[{"name":"implementation","type":"address"},{"name":"data","type":"bytes"}],"outputs":[],"stateMutability":"payable"}
+]`
+
+//ImplementationSlot is the EIP-1967 storage slot of the implementation address.
+var ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+//Proxy is an implementation contract used through a transparent proxy.
+//Contract has the ABI of the current implementation bound to the address of the proxy.
+type Proxy struct {
+	*Contract
+	Implementation *Contract         //deployed implementation
+	Admin          *ecdsa.PrivateKey //key of the account which can upgrade the proxy
+}
+
+//DeployProxy deploys a proxy to the implementation on its backend, and returns the implementation used through it.
+//The implementation is deployed without constructor's inputs if it is not deployed yet.
+//If initializer is not empty, the method is called with args through the proxy in the deploy tx.
+//The owner of the implementation is the admin of the proxy.
+func DeployProxy(impl *Contract, initializer string, args ...interface{}) (*Proxy, error) {
+	if impl.deployed() == false {
+		if err := impl.Deploy(); err != nil {
+			return nil, fmt.Errorf("deploy implementation: %v", err)
+		}
+	}
+	data, err := impl.initData(initializer, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := newEmbedded(proxySource, "TransparentProxy", impl.Backend, impl.OwnerKey)
+	if err != nil {
+		return nil, fmt.Errorf("compile proxy: %v", err)
+	}
+	if err := proxy.Deploy(impl.Address, impl.Owner, data); err != nil {
+		return nil, fmt.Errorf("deploy proxy: %v", err)
+	}
+
+	r := &Proxy{Implementation: impl, Admin: impl.OwnerKey}
+	r.bind(impl, proxy)
+	return r, nil
+}
+
+//initData packs the call of the initializer, or returns nil if it is empty.
+func (p *Contract) initData(initializer string, args ...interface{}) ([]byte, error) {
+	if initializer == "" {
+		return nil, nil
+	}
+	data, err := p.pack(initializer, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", initializer, err)
+	}
+	return data, nil
+}
+
+//bind sets Contract to a copy of the implementation at the address of the proxy, with the proxy's deployment.
+func (p *Proxy) bind(impl *Contract, proxy *Contract) {
+	c := *impl
+	c.Address = proxy.Address
+	c.BlockDeployed = proxy.BlockDeployed
+	c.ConstructorInputs = nil
+	c.DeployGas = proxy.DeployGas
+	c.Deployer = proxy.Deployer
+	c.DeployNonce = proxy.DeployNonce
+	c.OwnerKey, c.Owner = proxy.OwnerKey, proxy.Owner
+	p.Contract = &c
+}
+
+//Upgrade changes the implementation of the proxy to next, deploying it on the proxy's backend if it is not deployed,
+//and calls the initializer with args through the proxy if it is not empty.
+//next is compiled without a backend by Compile, or is on the proxy's backend.
+//Contract is replaced with the ABI of next bound to the proxy.
+func (p *Proxy) Upgrade(next *Contract, initializer string, args ...interface{}) error {
+	if next.Backend == nil {
+		next.Backend = p.Backend
+	} else if next.Backend != p.Backend {
+		return fmt.Errorf("%s implementation is on another backend", next.Name)
+	}
+	if next.deployed() == false {
+		if err := next.Deploy(); err != nil {
+			return fmt.Errorf("deploy implementation: %v", err)
+		}
+	}
+	data, err := next.initData(initializer, args...)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(proxyAdminAbi))
+	if err != nil {
+		return err
+	}
+	admin := &Contract{
+		Name:     "TransparentProxy",
+		Backend:  p.Backend,
+		OwnerKey: p.Admin,
+		Owner:    crypto.PubkeyToAddress(p.Admin.PublicKey),
+		Abi:      &parsed,
+		Address:  p.Address,
+	}
+	receipt, err := admin.Execute(nil, "upgradeToAndCall", next.Address, data)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != 1 {
+		reason, _ := admin.RevertReason(admin.Owner, "upgradeToAndCall", next.Address, data)
+		return fmt.Errorf("upgrade to %s failed: %s", next.Name, reason)
+	}
+
+	proxy := *p.Contract
+	p.Implementation = next
+	p.bind(next, &proxy)
+	return nil
+}
+
+//UpgradeAndCheck upgrades like Upgrade, and reports the probes whose results differ before and after the upgrade.
+//The differences have Step 0 and Kind "state".
+func (p *Proxy) UpgradeAndCheck(next *Contract, probes []Probe, initializer string, args ...interface{}) ([]Difference, error) {
+	before := make([]string, len(probes))
+	for i, probe := range probes {
+		ret, err := p.LowCall(probe.Method, probe.Args...)
+		before[i] = fmt.Sprint(ret, err)
+	}
+	if err := p.Upgrade(next, initializer, args...); err != nil {
+		return nil, err
+	}
+
+	diffs := []Difference{}
+	for i, probe := range probes {
+		ret, err := p.LowCall(probe.Method, probe.Args...)
+		if after := fmt.Sprint(ret, err); after != before[i] {
+			diffs = append(diffs, Difference{Kind: "state", What: probe.String(), Old: before[i], New: after})
+		}
+	}
+	return diffs, nil
+}
+
+//ImplementationAddress returns the implementation address in the EIP-1967 slot of the proxy.
+func (p *Proxy) ImplementationAddress() (common.Address, error) {
+	value, err := p.Backend.StorageAt(context.Background(), p.Address, ImplementationSlot, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(value), nil
+}
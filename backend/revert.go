@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//revertSelector is the id of Error(string), with which revert and require encode their reason.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+//UnpackRevert returns the reason in the output of a reverted call.
+func UnpackRevert(output []byte) (string, bool) {
+	if len(output) < 4 || bytes.Equal(output[:4], revertSelector) == false {
+		return "", false
+	}
+	data := output[4:]
+	if len(data) < 32 {
+		return "", false
+	}
+	offset := new(big.Int).SetBytes(data[:32])
+	if offset.Cmp(big.NewInt(int64(len(data)-32))) > 0 {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if length.Cmp(big.NewInt(int64(uint64(len(data))-start))) > 0 {
+		return "", false
+	}
+	return string(data[start : start+length.Uint64()]), true
+}
+
+//packRevert encodes the reason like revert and require do.
+func packRevert(reason string) []byte {
+	r := append([]byte{}, revertSelector...)
+	r = append(r, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	r = append(r, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	r = append(r, common.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+	return r
+}
+
+//RevertReason calls the method by the sender without a transaction,
+//and returns the reason if the call reverts with one.
+func (p *Contract) RevertReason(from common.Address, method string, args ...interface{}) (string, error) {
+	output, err := p.callFrom(from, method, args...)
+	if err != nil {
+		return "", err
+	}
+	reason, _ := UnpackRevert(output)
+	return reason, nil
+}
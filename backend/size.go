@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+const (
+	MaxCodeSize     = 24576           //runtime code size limit of EIP-170
+	MaxInitCodeSize = 2 * MaxCodeSize //init code size limit of EIP-3860
+)
+
+//SizeLimits are the thresholds checked by SizeReport.Check. Zero is no limit.
+type SizeLimits struct {
+	CodeSize     int
+	InitCodeSize int
+	DeployGas    uint64
+}
+
+//DefaultSizeLimits are the limits of EIP-170 and EIP-3860, without a deploy gas limit.
+var DefaultSizeLimits = SizeLimits{CodeSize: MaxCodeSize, InitCodeSize: MaxInitCodeSize}
+
+//SizeReport holds the code sizes and the deployment gas of a contract.
+type SizeReport struct {
+	Name         string
+	CodeSize     int    //runtime code
+	InitCodeSize int    //creation code with the constructor inputs
+	DeployGas    uint64 //used by the deploy tx, or estimated if not deployed
+	GasUnknown   bool   //DeployGas is not estimated, since the code is over the limits or there is no backend
+}
+
+//Size returns the size report of the contract.
+//The constructor inputs of the deployment are used, or args if it is not deployed.
+//The deploy gas of code over the limits of EIP-170 or EIP-3860 is not estimated,
+//since the deployment fails, and the report has GasUnknown.
+func (p *Contract) Size(args ...interface{}) (*SizeReport, error) {
+	r := &SizeReport{
+		Name:      p.Name,
+		CodeSize:  len(p.RuntimeCode),
+		DeployGas: p.DeployGas,
+	}
+	if p.BlockDeployed != nil {
+		args = p.ConstructorInputs
+	}
+	input, err := p.pack("", args...)
+	if err != nil {
+		return nil, err
+	}
+	initCode := append(append([]byte{}, p.Code...), input...)
+	r.InitCodeSize = len(initCode)
+
+	if p.BlockDeployed == nil {
+		if r.CodeSize > MaxCodeSize || r.InitCodeSize > MaxInitCodeSize || p.Backend == nil {
+			r.GasUnknown = true
+			return r, nil
+		}
+		gas, err := p.Backend.EstimateGas(context.Background(), ethereum.CallMsg{From: p.Owner, Data: initCode})
+		if err != nil {
+			return nil, fmt.Errorf("estimate deploy gas: %v", err)
+		}
+		r.DeployGas = gas
+	}
+	return r, nil
+}
+
+//Check returns an error describing every limit exceeded.
+func (p *SizeReport) Check(limits SizeLimits) error {
+	exceeded := []string{}
+	if limits.CodeSize > 0 && p.CodeSize > limits.CodeSize {
+		exceeded = append(exceeded, fmt.Sprintf("code size %d > %d", p.CodeSize, limits.CodeSize))
+	}
+	if limits.InitCodeSize > 0 && p.InitCodeSize > limits.InitCodeSize {
+		exceeded = append(exceeded, fmt.Sprintf("init code size %d > %d", p.InitCodeSize, limits.InitCodeSize))
+	}
+	if limits.DeployGas > 0 && p.GasUnknown == false && p.DeployGas > limits.DeployGas {
+		exceeded = append(exceeded, fmt.Sprintf("deploy gas %d > %d", p.DeployGas, limits.DeployGas))
+	}
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%s: %s", p.Name, strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+//String returns the sizes with the headroom against the limits of EIP-170 and EIP-3860.
+func (p *SizeReport) String() string {
+	gas := fmt.Sprint(p.DeployGas)
+	if p.GasUnknown == true {
+		gas = "unknown"
+	}
+	return fmt.Sprintf("%s: code %d bytes (%d left of %d), init code %d bytes (%d left of %d), deploy gas %s",
+		p.Name,
+		p.CodeSize, MaxCodeSize-p.CodeSize, MaxCodeSize,
+		p.InitCodeSize, MaxInitCodeSize-p.InitCodeSize, MaxInitCodeSize,
+		gas)
+}
@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+//stateContract is a contract recorded in the state file next to the genesis fields.
+type stateContract struct {
+	Artifact
+	OwnerKey hexutil.Bytes `json:"ownerKey,omitempty"`
+}
+
+//ExportState writes the state of the latest block of the backend to a geth genesis file:
+//balances, nonces, code and storage of every account in the alloc, and the block number and gas limit.
+//The contracts are recorded by alias in a "contracts" field, which geth ignores.
+func ExportState(b *Backend, path string, contracts map[string]*Contract) error {
+	head := b.Blockchain().CurrentBlock()
+	st, err := b.Blockchain().StateAt(head.Root())
+	if err != nil {
+		return err
+	}
+
+	alloc := core.GenesisAlloc{}
+	for address, account := range st.RawDump(false, false, true).Accounts {
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if ok == false {
+			return fmt.Errorf("%s: invalid balance: %s", address.Hex(), account.Balance)
+		}
+		ga := core.GenesisAccount{
+			Balance: balance,
+			Nonce:   account.Nonce,
+			Code:    common.FromHex(account.Code),
+		}
+		if len(account.Storage) > 0 {
+			ga.Storage = map[common.Hash]common.Hash{}
+			for key, value := range account.Storage {
+				ga.Storage[key] = common.HexToHash(value)
+			}
+		}
+		alloc[address] = ga
+	}
+
+	genesis := &core.Genesis{
+		Config:     b.Blockchain().Config(),
+		Number:     head.NumberU64(),
+		Timestamp:  head.Time(),
+		GasLimit:   head.GasLimit(),
+		Difficulty: head.Difficulty(),
+		Alloc:      alloc,
+	}
+	raw, err := json.Marshal(genesis)
+	if err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+
+	recorded := map[string]*stateContract{}
+	for alias, c := range contracts {
+		artifact, err := c.Artifact()
+		if err != nil {
+			return fmt.Errorf("%s contract: %v", alias, err)
+		}
+		sc := &stateContract{Artifact: *artifact}
+		if c.OwnerKey != nil {
+			sc.OwnerKey = crypto.FromECDSA(c.OwnerKey)
+		}
+		recorded[alias] = sc
+	}
+	if fields["contracts"], err = json.Marshal(recorded); err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+//ImportState starts a new backend from the genesis file written by ExportState or geth,
+//and returns the recorded contracts by alias bound to it.
+//The backend writes an empty block at the block number and timestamp of the file,
+//so block numbers and times go on from there.
+//The file is rejected if its chain config differs from the one of the simulated backend.
+func ImportState(path string) (*Backend, map[string]*Contract, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	genesis := &core.Genesis{}
+	if err := json.Unmarshal(raw, genesis); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	recorded := struct {
+		Contracts map[string]*stateContract `json:"contracts"`
+	}{}
+	if err := json.Unmarshal(raw, &recorded); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	gasLimit := genesis.GasLimit
+	if gasLimit == 0 {
+		gasLimit = 10000000
+	}
+	b := WrapBackend(backends.NewSimulatedBackend(genesis.Alloc, gasLimit))
+	if err := importChain(b, genesis); err != nil {
+		b.Close()
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	contracts := map[string]*Contract{}
+	for alias, sc := range recorded.Contracts {
+		c, err := sc.contract(b)
+		if err != nil {
+			b.Close()
+			return nil, nil, fmt.Errorf("%s contract: %v", alias, err)
+		}
+		contracts[alias] = c
+	}
+	return b, contracts, nil
+}
+
+//importChain checks the chain config of the genesis, and writes an empty block at its number and timestamp
+//on the genesis block of the backend, as writeCheats writes a block, so a large number is imported at once.
+//The blocks between them are not in the chain, so their hashes and headers are not found.
+func importChain(b *Backend, genesis *core.Genesis) error {
+	chain := b.Blockchain()
+	if genesis.Config != nil {
+		want, err := json.Marshal(chain.Config())
+		if err != nil {
+			return err
+		}
+		got, err := json.Marshal(genesis.Config)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(want, got) == false {
+			return fmt.Errorf("chain config differs from the simulated one: %s", got)
+		}
+	}
+
+	parent := chain.CurrentBlock()
+	if genesis.Number == 0 {
+		if genesis.Timestamp != parent.Time() {
+			return fmt.Errorf("timestamp %d of the genesis block can not be set", genesis.Timestamp)
+		}
+		return nil
+	}
+	if genesis.Timestamp <= parent.Time() {
+		return fmt.Errorf("timestamp %d is not after the genesis block at %d", genesis.Timestamp, parent.Time())
+	}
+	statedb, err := chain.StateAt(parent.Root())
+	if err != nil {
+		return err
+	}
+
+	//the total difficulty of the block is counted from its parent, looked up by the number before it
+	if genesis.Number > 1 {
+		db, ok := chain.StateCache().TrieDB().DiskDB().(ethdb.KeyValueWriter)
+		if ok == false {
+			return fmt.Errorf("database of the backend is not writable")
+		}
+		rawdb.WriteTd(db, parent.Hash(), genesis.Number-1, chain.GetTd(parent.Hash(), parent.NumberU64()))
+	}
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).SetUint64(genesis.Number),
+		GasLimit:   parent.GasLimit(),
+		Time:       genesis.Timestamp,
+	}
+	header.Difficulty = chain.Engine().CalcDifficulty(chain, header.Time, parent.Header())
+	header.Root = statedb.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	block := types.NewBlock(header, nil, nil, nil)
+	if _, err := chain.WriteBlockWithState(block, nil, nil, statedb, true); err != nil {
+		return err
+	}
+	b.Rollback() //the pending block on the new head
+	return nil
+}
+
+//contract returns the recorded contract bound to the backend, with its owner key if recorded.
+func (p *stateContract) contract(b *Backend) (*Contract, error) {
+	r, err := p.Artifact.Contract(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.OwnerKey) > 0 {
+		if r.OwnerKey, err = crypto.ToECDSA(p.OwnerKey); err != nil {
+			return nil, err
+		}
+		r.Owner = crypto.PubkeyToAddress(r.OwnerKey.PublicKey)
+	}
+	return r, nil
+}
@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+//Verify checks that the code at p.Address is the compiled runtime code.
+//The CBOR metadata appended by solc and the values of immutables are ignored.
+func (p *Contract) Verify() error {
+	if len(p.RuntimeCode) == 0 {
+		return fmt.Errorf("%s contract has no runtime code", p.Name)
+	}
+	deployed, err := p.Backend.CodeAt(context.Background(), p.Address, nil)
+	if err != nil {
+		return err
+	}
+	if len(deployed) == 0 {
+		return fmt.Errorf("no code at %s", p.Address.Hex())
+	}
+	if err := compareCode(p.RuntimeCode, deployed); err != nil {
+		return fmt.Errorf("code at %s: %v", p.Address.Hex(), err)
+	}
+	return nil
+}
+
+//VerifyArtifact compiles the source file of the artifact saved to the path again,
+//and checks that the creation and runtime code are the same as the saved ones but the CBOR metadata.
+//The deployed code is also verified if b is not nil and the artifact is deployed.
+func VerifyArtifact(path string, b *Backend) error {
+	saved, err := LoadContract(path, b)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		defer saved.Backend.Close()
+	}
+	compiled, err := Compile(saved.File, saved.Name)
+	if err != nil {
+		return err
+	}
+
+	if saved.Info.CompilerVersion != compiled.Info.CompilerVersion {
+		return fmt.Errorf("compiler version: saved %s, compiled %s", saved.Info.CompilerVersion, compiled.Info.CompilerVersion)
+	}
+	if bytes.Equal(stripMetadata(saved.Code), stripMetadata(compiled.Code)) == false {
+		return fmt.Errorf("creation code of %s differs from the compiled one", saved.Name)
+	}
+	if err := compareCode(compiled.RuntimeCode, saved.RuntimeCode); err != nil {
+		return fmt.Errorf("runtime code of %s: %v", saved.Name, err)
+	}
+
+	if b != nil && saved.deployed() {
+		saved.RuntimeCode = compiled.RuntimeCode
+		return saved.Verify()
+	}
+	return nil
+}
+
+//compareCode compares the runtime code with the compiled one but the CBOR metadata,
+//masking the immediates of PUSH32 that are zero in the compiled code, which are the places of immutables.
+func compareCode(compiled, code []byte) error {
+	compiled, code = stripMetadata(compiled), stripMetadata(code)
+	if len(compiled) != len(code) {
+		return fmt.Errorf("size %d, compiled %d", len(code), len(compiled))
+	}
+
+	masked := append([]byte{}, code...)
+	zero := make([]byte, 32)
+	for i := 0; i < len(compiled); i++ {
+		op := compiled[i]
+		if op < 0x60 || op > 0x7f { //not PUSH1...PUSH32
+			continue
+		}
+		n := int(op) - 0x5f
+		if op == 0x7f && i+1+n <= len(compiled) && bytes.Equal(compiled[i+1:i+1+n], zero) {
+			copy(masked[i+1:i+1+n], zero)
+		}
+		i += n
+	}
+
+	for i := range compiled {
+		if compiled[i] != masked[i] {
+			return fmt.Errorf("differs from the compiled code at byte %d", i)
+		}
+	}
+	return nil
+}
+
+//stripMetadata removes the CBOR metadata which solc appends to the code with its size in the last two bytes.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	size := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - size
+	if size == 0 || start < 0 {
+		return code
+	}
+	//a CBOR map of one to a few items
+	if code[start] < 0xa1 || code[start] > 0xa5 {
+		return code
+	}
+	return code[:start]
+}
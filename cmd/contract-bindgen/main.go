@@ -0,0 +1,44 @@
+//contract-bindgen generates typed Go wrappers of a solidity contract on top of backend.Contract.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+func main() {
+	sol := flag.String("sol", "", "solidity source file")
+	name := flag.String("name", "", "contract name in the source file")
+	pkg := flag.String("pkg", "main", "package name of the generated code")
+	out := flag.String("out", "", "output file, stdout if empty")
+	flag.Parse()
+
+	if *sol == "" || *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	contract, err := backend.NewContract(*sol, *name)
+	if err != nil {
+		fatal(err)
+	}
+	code, err := contract.Bind(*pkg)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *out == "" {
+		fmt.Print(code)
+	} else if err := ioutil.WriteFile(*out, []byte(code), 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "contract-bindgen:", err)
+	os.Exit(1)
+}
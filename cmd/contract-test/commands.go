@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//runCompile compiles the contract and prints its compiler information and ABI.
+func runCompile(e *env, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s", commands["compile"].usage)
+	}
+	contract, err := backend.NewContract(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	defer contract.Backend.Close()
+
+	methods := []string{}
+	for _, m := range contract.Abi.Methods {
+		methods = append(methods, m.String())
+	}
+	sort.Strings(methods)
+	events := []string{}
+	for _, ev := range contract.Abi.Events {
+		events = append(events, ev.String())
+	}
+	sort.Strings(events)
+
+	return e.out.print(result{
+		{"name", contract.Name},
+		{"file", contract.File},
+		{"compilerVersion", contract.Info.CompilerVersion},
+		{"codeSize", len(contract.Code)},
+		{"methods", methods},
+		{"events", events},
+	})
+}
+
+//runDeploy compiles and deploys the contract on the persistent chain.
+func runDeploy(e *env, args []string) error {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	from := flags.String("from", "owner", "account deploying the contract")
+	alias := flags.String("as", "", "alias of the deployed contract, the contract name if empty")
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		return fmt.Errorf("usage: %s", commands["deploy"].usage)
+	}
+
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	contract, err := backend.Compile(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	contract.Backend = sess.backend
+	contract.Lenient = true
+	if contract.OwnerKey, err = sess.key(*from); err != nil {
+		return err
+	}
+	contract.Owner = crypto.PubkeyToAddress(contract.OwnerKey.PublicKey)
+
+	if err := contract.Deploy(sess.resolve(flags.Args()[2:])...); err != nil {
+		return err
+	}
+	if *alias == "" {
+		*alias = contract.Name
+	}
+	if err := sess.deploy(*alias, contract); err != nil {
+		return err
+	}
+	if err := sess.save(); err != nil {
+		return err
+	}
+
+	return e.out.print(result{
+		{"contract", *alias},
+		{"address", contract.Address.Hex()},
+		{"block", contract.BlockDeployed.String()},
+		{"deployer", contract.Owner.Hex()},
+	})
+}
+
+//runCall invokes a view method and prints the decoded outputs.
+func runCall(e *env, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %s", commands["call"].usage)
+	}
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	contract, err := sess.contract(args[0])
+	if err != nil {
+		return err
+	}
+
+	values, err := contract.LowCall(args[1], sess.resolve(args[2:])...)
+	if err != nil {
+		return err
+	}
+	return e.out.print(outputs(contract, args[1], values))
+}
+
+//runSend sends a transaction to the method and prints the receipt.
+func runSend(e *env, args []string) error {
+	flags := flag.NewFlagSet("send", flag.ExitOnError)
+	from := flags.String("from", "owner", "account sending the transaction")
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		return fmt.Errorf("usage: %s", commands["send"].usage)
+	}
+
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	contract, err := sess.contract(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	key, err := sess.key(*from)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := contract.Execute(key, flags.Arg(1), sess.resolve(flags.Args()[2:])...)
+	if err != nil {
+		return err
+	}
+	if err := sess.save(); err != nil {
+		return err
+	}
+
+	contracts := []*backend.Contract{}
+	for alias := range sess.state.Contracts {
+		if c, err := sess.contract(alias); err == nil {
+			contracts = append(contracts, c)
+		}
+	}
+	return e.out.print(receiptResult(receipt, contracts...))
+}
+
+//runMine makes empty blocks.
+func runMine(e *env, args []string) error {
+	n := uint64(1)
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+			return fmt.Errorf("usage: %s", commands["mine"].usage)
+		}
+	}
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < n; i++ {
+		sess.backend.Commit() //make block
+	}
+	if err := sess.save(); err != nil {
+		return err
+	}
+	return e.out.print(result{{"block", sess.backend.Blockchain().CurrentBlock().Number().String()}})
+}
+
+//runAccount makes the named accounts if they are not here, and prints the addresses of the accounts.
+func runAccount(e *env, args []string) error {
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if _, err := sess.key(name); err != nil {
+			return err
+		}
+	}
+	if err := sess.save(); err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		for name := range sess.state.Keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	r := result{}
+	for _, name := range names {
+		r = append(r, field{name, sess.address(name)})
+	}
+	return e.out.print(r)
+}
+
+//runReset removes the state file, so that the next command starts a new chain.
+func runReset(e *env, args []string) error {
+	if err := os.Remove(e.statePath); err != nil && os.IsNotExist(err) == false {
+		return err
+	}
+	return e.out.print(result{{"removed", e.statePath}})
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/peterh/liner"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+const consoleHelp = `commands:
+  deploy <file.sol> <name> [args...]   compile and deploy a contract, and use it
+  use <contract>                       use a deployed contract
+  [as <account>] <method> [args...]    send a transaction, or call a view method, from the account
+  call <method> [args...]              call a method without a transaction
+  help [method]                        show this help or the inputs of the method
+  mine [blocks]                        make empty blocks
+  block                                show the current block number
+  accounts                             show the accounts
+  snapshot                             save the chain state and show its id
+  revert [id]                          restore the chain state of the snapshot, the last one if no id
+  exit                                 save the chain and quit
+Arguments with spaces are quoted like "0.5 ether". Account and contract names are replaced with their addresses.`
+
+//snapshot is a saved chain state of the console.
+type snapshot struct {
+	journal   *backend.Journal
+	contracts map[string]*deployment
+}
+
+//console executes the commands on the session.
+type console struct {
+	e         *env
+	sess      *session
+	current   string //alias of the contract in use
+	snapshots []snapshot
+}
+
+//runConsole starts an interactive console on the persistent chain.
+func runConsole(e *env, args []string) error {
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	c := &console{e: e, sess: sess}
+	if len(args) > 0 {
+		if _, err := sess.contract(args[0]); err != nil {
+			return err
+		}
+		c.current = args[0]
+	}
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(c.complete)
+
+	history := filepath.Join(os.TempDir(), ".contract-test_history")
+	if f, err := os.Open(history); err == nil {
+		line.ReadHistory(f)
+		f.Close()
+	}
+
+	for {
+		input, err := line.Prompt(c.prompt())
+		if err == liner.ErrPromptAborted {
+			continue
+		} else if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		words, err := splitWords(input)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		line.AppendHistory(input)
+		if words[0] == "exit" || words[0] == "quit" {
+			break
+		}
+		if err := c.execute(words); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+	}
+
+	if f, err := os.Create(history); err == nil {
+		line.WriteHistory(f)
+		f.Close()
+	}
+	return sess.save()
+}
+
+func (p *console) prompt() string {
+	block := p.sess.backend.Blockchain().CurrentBlock().Number()
+	if p.current == "" {
+		return fmt.Sprintf("#%v> ", block)
+	}
+	return fmt.Sprintf("%s #%v> ", p.current, block)
+}
+
+//execute executes a command given in words.
+func (p *console) execute(words []string) error {
+	switch words[0] {
+	case "help":
+		return p.help(words[1:])
+	case "deploy":
+		return p.deploy(words[1:])
+	case "use":
+		if len(words) != 2 {
+			return fmt.Errorf("usage: use <contract>")
+		}
+		if _, err := p.sess.contract(words[1]); err != nil {
+			return err
+		}
+		p.current = words[1]
+		return nil
+	case "mine":
+		n := uint64(1)
+		if len(words) > 1 {
+			var err error
+			if n, err = strconv.ParseUint(words[1], 10, 64); err != nil {
+				return fmt.Errorf("usage: mine [blocks]")
+			}
+		}
+		for i := uint64(0); i < n; i++ {
+			p.sess.backend.Commit() //make block
+		}
+		return p.block()
+	case "block":
+		return p.block()
+	case "accounts":
+		names := []string{}
+		for name := range p.sess.state.Keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		r := result{}
+		for _, name := range names {
+			r = append(r, field{name, p.sess.address(name)})
+		}
+		return p.e.out.print(r)
+	case "snapshot":
+		return p.snapshot()
+	case "revert":
+		return p.revert(words[1:])
+	case "call":
+		if len(words) < 2 {
+			return fmt.Errorf("usage: call <method> [args...]")
+		}
+		return p.call("", words[1], words[2:])
+	case "as":
+		if len(words) < 3 {
+			return fmt.Errorf("usage: as <account> <method> [args...]")
+		}
+		return p.method(words[1], words[2], words[3:])
+	}
+	return p.method("", words[0], words[1:])
+}
+
+func (p *console) help(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(p.e.out.w, consoleHelp)
+		return nil
+	}
+	contract, err := p.contract()
+	if err != nil {
+		return err
+	}
+	m, ok := contract.Abi.Methods[args[0]]
+	if ok == false {
+		return fmt.Errorf("%s method is not here", args[0])
+	}
+	fmt.Fprintln(p.e.out.w, m.String())
+	return nil
+}
+
+func (p *console) deploy(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: deploy <file.sol> <name> [args...]")
+	}
+	contract, err := backend.Compile(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	contract.Backend = p.sess.backend
+	contract.Lenient = true
+	if contract.OwnerKey, err = p.sess.key("owner"); err != nil {
+		return err
+	}
+	contract.Owner = crypto.PubkeyToAddress(contract.OwnerKey.PublicKey)
+
+	if err := contract.Deploy(p.sess.resolve(args[2:])...); err != nil {
+		return err
+	}
+	if err := p.sess.deploy(contract.Name, contract); err != nil {
+		return err
+	}
+	p.current = contract.Name
+	return p.e.out.print(result{
+		{"contract", contract.Name},
+		{"address", contract.Address.Hex()},
+		{"block", contract.BlockDeployed.String()},
+	})
+}
+
+func (p *console) contract() (*backend.Contract, error) {
+	if p.current == "" {
+		return nil, fmt.Errorf("no contract in use, deploy or use one")
+	}
+	return p.sess.contract(p.current)
+}
+
+//method calls a view method, or sends a transaction to the method, from the account.
+//Without an account, the owner sends the transaction, and the view method is called from the zero address.
+func (p *console) method(account, method string, args []string) error {
+	contract, err := p.contract()
+	if err != nil {
+		return err
+	}
+	m, ok := contract.Abi.Methods[method]
+	if ok == false {
+		return fmt.Errorf("unknown command or method: %s", method)
+	}
+	if m.Const == true {
+		return p.call(account, method, args)
+	}
+
+	if account == "" {
+		account = "owner"
+	}
+	key, err := p.sess.key(account)
+	if err != nil {
+		return err
+	}
+	receipt, err := contract.Execute(key, method, p.sess.resolve(args)...)
+	if err != nil {
+		return err
+	}
+	return p.e.out.print(receiptResult(receipt, contract))
+}
+
+//call calls the method without a transaction, from the account if it is not empty.
+func (p *console) call(account, method string, args []string) error {
+	contract, err := p.contract()
+	if err != nil {
+		return err
+	}
+	from := common.Address{}
+	if account != "" {
+		key, err := p.sess.key(account)
+		if err != nil {
+			return err
+		}
+		from = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	values, err := contract.LowCallFrom(from, method, p.sess.resolve(args)...)
+	if err != nil {
+		return err
+	}
+	return p.e.out.print(outputs(contract, method, values))
+}
+
+func (p *console) block() error {
+	return p.e.out.print(result{{"block", p.sess.backend.Blockchain().CurrentBlock().Number().String()}})
+}
+
+func (p *console) snapshot() error {
+	journal, err := backend.RecordJournal(p.sess.backend)
+	if err != nil {
+		return err
+	}
+	contracts := map[string]*deployment{}
+	for alias, d := range p.sess.state.Contracts {
+		contracts[alias] = d
+	}
+	p.snapshots = append(p.snapshots, snapshot{journal: journal, contracts: contracts})
+	return p.e.out.print(result{{"snapshot", len(p.snapshots) - 1}})
+}
+
+//revert makes the chain of the snapshot again on a new backend.
+//The snapshots taken after it are removed.
+func (p *console) revert(args []string) error {
+	id := len(p.snapshots) - 1
+	if len(args) > 0 {
+		var err error
+		if id, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("usage: revert [id]")
+		}
+	}
+	if id < 0 || id >= len(p.snapshots) {
+		return fmt.Errorf("no snapshot %d", id)
+	}
+
+	s := p.snapshots[id]
+	b := backend.NewBackend()
+	if err := s.journal.Replay(b); err != nil {
+		b.Close()
+		return err
+	}
+	p.sess.backend.Close()
+	p.sess.backend = b
+	p.sess.state.Contracts = s.contracts
+	if _, ok := s.contracts[p.current]; ok == false {
+		p.current = ""
+	}
+	p.snapshots = p.snapshots[:id+1]
+	return p.block()
+}
+
+//complete completes commands and method names, and account names for address and bool inputs.
+//For the other inputs, it shows the type and the name of the input being typed, like <uint256 amount>.
+func (p *console) complete(line string) []string {
+	words, err := splitWords(line)
+	if err != nil {
+		return nil
+	}
+	if strings.HasSuffix(line, " ") || len(words) == 0 {
+		words = append(words, "")
+	}
+	prefix := strings.Join(words[:len(words)-1], " ")
+	if prefix != "" {
+		prefix += " "
+	}
+	last := words[len(words)-1]
+
+	contract, _ := p.contract()
+	candidates := []string{}
+	switch {
+	case len(words) == 1:
+		candidates = append(candidates, "help", "deploy", "use", "call", "as", "mine", "block", "accounts", "snapshot", "revert", "exit")
+		candidates = append(candidates, p.methods(contract)...)
+	case words[0] == "use":
+		for alias := range p.sess.state.Contracts {
+			candidates = append(candidates, alias)
+		}
+	case words[0] == "as" && len(words) == 2:
+		for name := range p.sess.state.Keys {
+			candidates = append(candidates, name)
+		}
+	case (words[0] == "call" || words[0] == "help") && len(words) == 2, words[0] == "as" && len(words) == 3:
+		candidates = p.methods(contract)
+	default:
+		candidates = p.arguments(contract, words)
+	}
+
+	ret := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, last) {
+			ret = append(ret, prefix+c)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (p *console) methods(contract *backend.Contract) []string {
+	if contract == nil {
+		return nil
+	}
+	ret := []string{}
+	for name := range contract.Abi.Methods {
+		ret = append(ret, name)
+	}
+	return ret
+}
+
+//arguments returns the candidates of the argument being typed by the input's type,
+//or a placeholder with the type and the name of the input.
+func (p *console) arguments(contract *backend.Contract, words []string) []string {
+	if contract == nil {
+		return nil
+	}
+	i := 0 //index of the method name in words
+	switch words[0] {
+	case "call":
+		i = 1
+	case "as":
+		i = 2
+	}
+	m, ok := contract.Abi.Methods[words[i]]
+	if ok == false {
+		return nil
+	}
+	n := len(words) - i - 2 //index of the input being typed
+	if n < 0 || n >= len(m.Inputs) {
+		return nil
+	}
+
+	input := m.Inputs[n]
+	switch input.Type.T {
+	case abi.AddressTy:
+		ret := []string{}
+		for name := range p.sess.state.Keys {
+			ret = append(ret, name)
+		}
+		for alias := range p.sess.state.Contracts {
+			ret = append(ret, alias)
+		}
+		return ret
+	case abi.BoolTy:
+		return []string{"true", "false"}
+	}
+	if input.Name == "" {
+		return []string{fmt.Sprintf("<%s>", input.Type.String())}
+	}
+	return []string{fmt.Sprintf("<%s %s>", input.Type.String(), input.Name)}
+}
+
+//splitWords splits the line by spaces, keeping the spaces in double quotes.
+func splitWords(line string) ([]string, error) {
+	words := []string{}
+	word, quoted, inWord := strings.Builder{}, false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case r == ' ' && quoted == false:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
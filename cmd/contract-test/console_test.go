@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//newTestConsole returns a console on a new state file using WemixToken deployed by the owner,
+//which prints the results as JSON to buf.
+func newTestConsole(t *testing.T, buf *bytes.Buffer) *console {
+	e := &env{statePath: filepath.Join(t.TempDir(), "state.json"), out: &printer{json: true, w: buf}}
+	sess, err := openSession(e.statePath)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		sess.backend.Close()
+	})
+	for _, name := range []string{"ecoFund", "wemix", "alice"} {
+		_, err := sess.key(name)
+		assert.NoError(t, err)
+	}
+
+	c := &console{e: e, sess: sess}
+	assert.NoError(t, c.execute([]string{"deploy", wemixFile, "WemixToken", "ecoFund", "wemix"}))
+	return c
+}
+
+//Test to split console lines into words.
+func TestSplitWords(t *testing.T) {
+	for _, c := range []struct {
+		line     string
+		expected []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"a b  c ", []string{"a", "b", "c"}},
+		{`transfer alice "0.5 ether"`, []string{"transfer", "alice", "0.5 ether"}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`say ""`, []string{"say", ""}},
+	} {
+		words, err := splitWords(c.line)
+		assert.NoError(t, err, c.line)
+		assert.Equal(t, c.expected, words, c.line)
+	}
+
+	_, err := splitWords(`say "unterminated`)
+	assert.Error(t, err)
+}
+
+//Test to complete commands, aliases, accounts, methods and address inputs.
+func TestConsoleComplete(t *testing.T) {
+	c := newTestConsole(t, &bytes.Buffer{})
+
+	all := c.complete("")
+	for _, expected := range []string{"accounts", "as", "exit", "transfer", "balanceOf"} {
+		assert.Contains(t, all, expected)
+	}
+	for line, expected := range map[string][]string{
+		"us":                   {"use"},
+		"use ":                 {"use WemixToken"},
+		"as a":                 {"as alice"},
+		"as alice bal":         {"as alice balanceOf"},
+		"call bal":             {"call balanceOf"},
+		"help isO":             {"help isOwner"},
+		"transfer a":           {"transfer alice"},
+		"as alice transfer w":  {"as alice transfer wemix"},
+		"call balanceOf W":     {"call balanceOf WemixToken"},
+		"transfer alice ":      {"transfer alice <uint256 amount>"},
+		"transfer alice 1":     {},
+		"stake ":               {"stake <uint256 _withdrawalWaitingMinBlock>"},
+		"unknown a":            {},
+		"transfer alice 1 a":   {},
+		"addAllowedPartner e":  {"addAllowedPartner ecoFund"},
+		"as owner approve own": {"as owner approve owner"},
+	} {
+		assert.Equal(t, expected, c.complete(line), line)
+	}
+	assert.Nil(t, c.complete(`transfer "a`))
+
+	//no contract in use
+	c.current = ""
+	assert.Equal(t, []string{}, c.complete("transfer a"))
+	assert.Equal(t, []string{"use WemixToken"}, c.complete("use W"))
+	assert.NotContains(t, c.complete(""), "transfer")
+}
+
+//Test to call view methods and send transactions from the account given by as.
+func TestConsoleAs(t *testing.T) {
+	buf := bytes.Buffer{}
+	c := newTestConsole(t, &buf)
+	execute := func(words ...string) map[string]interface{} {
+		buf.Reset()
+		assert.NoError(t, c.execute(words))
+		r := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+		return r
+	}
+
+	//view methods are called from the account, and from the zero address without it
+	assert.Equal(t, true, execute("as", "owner", "isOwner")["0"])
+	assert.Equal(t, false, execute("as", "alice", "isOwner")["0"])
+	assert.Equal(t, false, execute("isOwner")["0"])
+	assert.Equal(t, false, execute("call", "isOwner")["0"])
+
+	//transactions are sent by the account, and by the owner without it
+	assert.Equal(t, float64(1), execute("as", "alice", "approve", "wemix", "7")["status"])
+	assert.Equal(t, float64(1), execute("approve", "wemix", "9")["status"])
+	assert.Equal(t, "7", execute("allowance", "alice", "wemix")["0"])
+	assert.Equal(t, "9", execute("allowance", "owner", "wemix")["0"])
+
+	assert.Error(t, c.execute([]string{"as", "alice"}))
+	assert.Error(t, c.execute([]string{"as", "alice", "unknown"}))
+}
+
+//Test to revert to a snapshot taken after a time change, and get the same head block.
+func TestConsoleSnapshotTime(t *testing.T) {
+	c := newTestConsole(t, &bytes.Buffer{})
+
+	assert.NoError(t, c.sess.backend.AdjustTime(time.Hour))
+	assert.NoError(t, c.execute([]string{"mine"}))
+	head := c.sess.backend.Blockchain().CurrentBlock()
+	assert.NoError(t, c.execute([]string{"snapshot"}))
+	assert.NoError(t, c.execute([]string{"mine", "2"}))
+
+	assert.NoError(t, c.execute([]string{"revert"}))
+	restored := c.sess.backend.Blockchain().CurrentBlock()
+	assert.Equal(t, head.Time(), restored.Time())
+	assert.Equal(t, head.Hash(), restored.Hash())
+}
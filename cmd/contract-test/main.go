@@ -0,0 +1,82 @@
+//contract-test compiles, deploys and executes solidity contracts on a persistent local chain
+//simulated by backend.Contract.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//command is a subcommand with its usage.
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+//commands is set in init, since the commands refer to their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"compile":  {"compile <file.sol> <name>", runCompile},
+		"deploy":   {"deploy [-from account] [-as alias] <file.sol> <name> [args...]", runDeploy},
+		"call":     {"call <contract> <method> [args...]", runCall},
+		"send":     {"send [-from account] <contract> <method> [args...]", runSend},
+		"mine":     {"mine [blocks]", runMine},
+		"account":  {"account [name...]", runAccount},
+		"console":  {"console [contract]", runConsole},
+		"reset":    {"reset", runReset},
+		"scenario": {"scenario <file.yaml|file.json...>", runScenario},
+		"serve":    {"serve [-addr host:port]", runServe},
+	}
+}
+
+//env holds the global options.
+type env struct {
+	statePath string
+	out       *printer
+}
+
+func main() {
+	flags := flag.NewFlagSet("contract-test", flag.ExitOnError)
+	statePath := flags.String("state", "contract-test.json", "state file of the persistent local chain")
+	jsonOut := flags.Bool("json", false, "print results as JSON")
+	flags.Usage = func() {
+		usage(flags)
+	}
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if ok == false {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", flags.Arg(0))
+		usage(flags)
+		os.Exit(2)
+	}
+
+	e := &env{statePath: *statePath, out: &printer{json: *jsonOut, w: os.Stdout}}
+	if err := cmd.run(e, flags.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "contract-test:", err)
+		os.Exit(1)
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "usage: contract-test [-state file] [-json] <command> [args...]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\noptions:")
+	flags.PrintDefaults()
+}
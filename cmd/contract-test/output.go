@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//field is a named value of a result.
+type field struct {
+	Key   string
+	Value interface{}
+}
+
+//result is an ordered list of fields, printed as lines of text or as a JSON object.
+type result []field
+
+func (p result) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, f := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+//printer prints results as text or JSON.
+type printer struct {
+	json bool
+	w    io.Writer
+}
+
+func (p *printer) print(r result) error {
+	if p.json == true {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	}
+	writeText(p.w, r, "")
+	return nil
+}
+
+func writeText(w io.Writer, r result, indent string) {
+	for _, f := range r {
+		switch v := f.Value.(type) {
+		case result:
+			fmt.Fprintf(w, "%s%s:\n", indent, f.Key)
+			writeText(w, v, indent+"  ")
+		case []string:
+			fmt.Fprintf(w, "%s%s:\n", indent, f.Key)
+			for _, e := range v {
+				fmt.Fprintf(w, "%s  %s\n", indent, e)
+			}
+		case []result:
+			fmt.Fprintf(w, "%s%s:\n", indent, f.Key)
+			for i, e := range v {
+				fmt.Fprintf(w, "%s  [%d]\n", indent, i)
+				writeText(w, e, indent+"    ")
+			}
+		default:
+			fmt.Fprintf(w, "%s%s: %v\n", indent, f.Key, v)
+		}
+	}
+}
+
+//formatValue converts a value unpacked from the ABI to a string or a slice of them.
+func formatValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *big.Int:
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case string, bool:
+		return x
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		ret := make([]interface{}, rv.Len())
+		for i := range ret {
+			ret[i] = formatValue(rv.Index(i).Interface())
+		}
+		return ret
+	}
+	return fmt.Sprint(v)
+}
+
+//outputs makes a result from the values returned by a method.
+func outputs(contract *backend.Contract, method string, values []interface{}) result {
+	r := result{}
+	for i, v := range values {
+		name := contract.Abi.Methods[method].Outputs[i].Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		r = append(r, field{name, formatValue(v)})
+	}
+	return r
+}
+
+//receiptResult makes a result from the receipt with the decoded events of the contracts.
+func receiptResult(receipt *types.Receipt, contracts ...*backend.Contract) result {
+	events := []result{}
+	for _, g := range receipt.Logs {
+		e := result{{"address", g.Address.Hex()}}
+		for _, c := range contracts {
+			if c.Address != g.Address {
+				continue
+			}
+			if name, values, err := c.DecodeLog(g); err == nil {
+				e = append(e, field{"event", name})
+				args := result{}
+				for _, input := range c.Abi.Events[name].Inputs {
+					args = append(args, field{input.Name, formatValue(values[input.Name])})
+				}
+				e = append(e, field{"args", args})
+			}
+		}
+		if len(e) == 1 {
+			topics := make([]string, len(g.Topics))
+			for i, t := range g.Topics {
+				topics[i] = t.Hex()
+			}
+			e = append(e, field{"topics", strings.Join(topics, ",")}, field{"data", hexutil.Encode(g.Data)})
+		}
+		events = append(events, e)
+	}
+
+	r := result{
+		{"tx", receipt.TxHash.Hex()},
+		{"status", receipt.Status},
+		{"block", receipt.BlockNumber.String()},
+		{"gasUsed", receipt.GasUsed},
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		r = append(r, field{"contractAddress", receipt.ContractAddress.Hex()})
+	}
+	return append(r, field{"events", events})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+const wemixFile = "../../contracts/WemixToken.sol"
+
+//Test to format the values unpacked from the ABI.
+func TestFormatValue(t *testing.T) {
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	hash := common.HexToHash("0x01")
+
+	for _, c := range []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{big.NewInt(-5), "-5"},
+		{address, address.Hex()},
+		{hash, hash.Hex()},
+		{[]byte{1, 2}, "0x0102"},
+		{"text", "text"},
+		{true, true},
+		{uint8(7), "7"},
+		{[4]byte{1, 2, 3, 4}, "0x01020304"},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2)}, []interface{}{"1", "2"}},
+		{[2]common.Address{address, address}, []interface{}{address.Hex(), address.Hex()}},
+		{[][]byte{{0xff}}, []interface{}{"0xff"}},
+	} {
+		assert.Equal(t, c.expected, formatValue(c.value), "%T", c.value)
+	}
+}
+
+//Test to print fields of a result as a JSON object in order.
+func TestResultMarshalJSON(t *testing.T) {
+	r := result{
+		{"b", 1},
+		{"a", result{{"z", "x"}}},
+		{"list", []string{"p", "q"}},
+		{"events", []result{{{"event", "Transfer"}}}},
+	}
+	b, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"b":1,"a":{"z":"x"},"list":["p","q"],"events":[{"event":"Transfer"}]}`, string(b))
+
+	b, err = json.Marshal(result{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+
+	_, err = json.Marshal(result{{"f", func() {}}})
+	assert.Error(t, err)
+
+	//text
+	buf := bytes.Buffer{}
+	assert.NoError(t, (&printer{w: &buf}).print(r))
+	assert.Equal(t, "b: 1\na:\n  z: x\nlist:\n  p\n  q\nevents:\n  [0]\n    event: Transfer\n", buf.String())
+}
+
+//Test to name the outputs of a method, by their index if they have no name.
+func TestOutputs(t *testing.T) {
+	contract, err := backend.Compile(wemixFile, "WemixToken")
+	assert.NoError(t, err)
+
+	assert.Equal(t, result{{"0", "10"}}, outputs(contract, "balanceOf", []interface{}{big.NewInt(10)}))
+
+	partner := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	payer := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	r := outputs(contract, "partnerBySerial", []interface{}{
+		big.NewInt(1), partner, payer, big.NewInt(2), big.NewInt(3), big.NewInt(4),
+	})
+	assert.Equal(t, result{
+		{"serial", "1"},
+		{"partner", partner.Hex()},
+		{"payer", payer.Hex()},
+		{"blockStaking", "2"},
+		{"blockWaitingWithdrawal", "3"},
+		{"balanceStaking", "4"},
+	}, r)
+}
+
+//Test to decode the events of a receipt emitted by the given contracts, and keep the raw logs of the others.
+func TestReceiptResult(t *testing.T) {
+	contract, err := backend.Compile(wemixFile, "WemixToken")
+	assert.NoError(t, err)
+	contract.Address = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	other := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	transfer := contract.Abi.Events["Transfer"].Id()
+	receipt := &types.Receipt{
+		Status:      1,
+		TxHash:      common.HexToHash("0x1234"),
+		BlockNumber: big.NewInt(3),
+		GasUsed:     21000,
+		Logs: []*types.Log{
+			{
+				Address: contract.Address,
+				Topics:  []common.Hash{transfer, from.Hash(), to.Hash()},
+				Data:    common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+			},
+			{
+				Address: other,
+				Topics:  []common.Hash{transfer},
+				Data:    []byte{1},
+			},
+		},
+	}
+
+	assert.Equal(t, result{
+		{"tx", receipt.TxHash.Hex()},
+		{"status", uint64(1)},
+		{"block", "3"},
+		{"gasUsed", uint64(21000)},
+		{"events", []result{
+			{
+				{"address", contract.Address.Hex()},
+				{"event", "Transfer"},
+				{"args", result{{"from", from.Hex()}, {"to", to.Hex()}, {"value", "100"}}},
+			},
+			{
+				{"address", other.Hex()},
+				{"topics", transfer.Hex()},
+				{"data", "0x01"},
+			},
+		}},
+	}, receiptResult(receipt, contract))
+
+	//deployment
+	receipt.ContractAddress = contract.Address
+	receipt.Logs = nil
+	r := receiptResult(receipt)
+	assert.Equal(t, field{"contractAddress", contract.Address.Hex()}, r[4])
+	assert.Equal(t, field{"events", []result{}}, r[5])
+}
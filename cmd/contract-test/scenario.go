@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wemade-tree/contract-test/backend"
+	"github.com/wemade-tree/contract-test/scenario"
+)
+
+//reporter prints the results of a scenario and counts the errors.
+type reporter struct {
+	e      *env
+	errors int
+}
+
+func (p *reporter) Logf(format string, args ...interface{}) {
+	fmt.Fprintf(p.e.out.w, "    "+format+"\n", args...)
+}
+
+func (p *reporter) Errorf(format string, args ...interface{}) {
+	p.errors++
+	fmt.Fprintf(p.e.out.w, "    FAIL "+format+"\n", args...)
+}
+
+//runScenario runs the scenario files on new chains, not on the persistent chain.
+func runScenario(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", commands["scenario"].usage)
+	}
+	failed := 0
+	for _, path := range args {
+		s, err := scenario.Load(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out.w, "%s: %s\n", path, s.Name)
+
+		r := &reporter{e: e}
+		if err := s.Run(r); err != nil {
+			r.Errorf("%v", err)
+		}
+		if r.errors > 0 {
+			failed++
+			fmt.Fprintf(e.out.w, "FAIL %s\n", path)
+		} else {
+			fmt.Fprintf(e.out.w, "ok   %s\n", path)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(e.out.w, "reproduce with %s=%s\n", backend.SeedEnv, backend.Seed())
+		return fmt.Errorf("%d of %d scenarios failed", failed, len(args))
+	}
+	return nil
+}
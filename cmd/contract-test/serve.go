@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/wemade-tree/contract-test/server"
+)
+
+//runServe serves the persistent chain as a JSON-RPC endpoint until interrupted, and then saves it.
+func runServe(e *env, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "127.0.0.1:8545", "address to listen on for HTTP and WebSocket")
+	flags.Parse(args)
+
+	sess, err := openSession(e.statePath)
+	if err != nil {
+		return err
+	}
+	srv, err := server.New(sess.backend)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	names := []string{}
+	for name := range sess.state.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	accounts := []result{}
+	for _, name := range names {
+		accounts = append(accounts, result{
+			{"account", name},
+			{"address", sess.address(name)},
+			{"key", hexutil.Encode(sess.state.Keys[name])},
+		})
+	}
+	if err := e.out.print(accounts); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "serving http://%s and ws://%s, interrupt to save and stop\n", *addr, *addr)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe(*addr)
+	}()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	select {
+	case err = <-errc:
+	case <-interrupt:
+	}
+
+	sess.backend = srv.Backend() //replaced by evm_revert
+	if err := sess.save(); err != nil {
+		return err
+	}
+	return err
+}
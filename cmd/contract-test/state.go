@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//deployment is a contract deployed on the persistent chain.
+type deployment struct {
+	File    string          `json:"file"`
+	Name    string          `json:"name"`
+	Address common.Address  `json:"address"`
+	Abi     json.RawMessage `json:"abi"`
+	Code    hexutil.Bytes   `json:"code"`
+}
+
+//state is saved in the state file between invocations.
+type state struct {
+	Keys      map[string]hexutil.Bytes `json:"keys"`      //private keys by account name
+	Contracts map[string]*deployment   `json:"contracts"` //deployed contracts by alias
+	Journal   *backend.Journal         `json:"journal"`
+}
+
+//session is the persistent chain made again from the state file.
+type session struct {
+	path    string
+	state   *state
+	backend *backend.Backend
+}
+
+//openSession reads the state file, if any, and replays its journal on a new backend.
+func openSession(path string) (*session, error) {
+	st := &state{
+		Keys:      map[string]hexutil.Bytes{},
+		Contracts: map[string]*deployment{},
+		Journal:   &backend.Journal{},
+	}
+	if b, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(b, st); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	} else if os.IsNotExist(err) == false {
+		return nil, err
+	}
+
+	r := &session{path: path, state: st, backend: backend.NewBackend()}
+	if err := st.Journal.Replay(r.backend); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if _, err := r.key("owner"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//save records the journal of the backend and writes the state file.
+func (p *session) save() error {
+	journal, err := backend.RecordJournal(p.backend)
+	if err != nil {
+		return err
+	}
+	p.state.Journal = journal
+
+	b, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.path, b, 0644)
+}
+
+//key returns the private key of the named account, making it if it is not here.
+func (p *session) key(name string) (*ecdsa.PrivateKey, error) {
+	if raw, ok := p.state.Keys[name]; ok == true {
+		return crypto.ToECDSA(raw)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	p.state.Keys[name] = crypto.FromECDSA(key)
+	return key, nil
+}
+
+//deploy records the deployed contract by the alias.
+func (p *session) deploy(alias string, contract *backend.Contract) error {
+	abiBytes, err := json.Marshal(contract.Info.AbiDefinition)
+	if err != nil {
+		return err
+	}
+	p.state.Contracts[alias] = &deployment{
+		File:    contract.File,
+		Name:    contract.Name,
+		Address: contract.Address,
+		Abi:     abiBytes,
+		Code:    contract.Code,
+	}
+	return nil
+}
+
+//contract returns the deployed contract by its alias, bound to the backend of the session.
+func (p *session) contract(alias string) (*backend.Contract, error) {
+	d, ok := p.state.Contracts[alias]
+	if ok == false {
+		return nil, fmt.Errorf("%s contract is not deployed", alias)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(d.Abi))
+	if err != nil {
+		return nil, err
+	}
+	owner, err := p.key("owner")
+	if err != nil {
+		return nil, err
+	}
+	return &backend.Contract{
+		File:     d.File,
+		Name:     d.Name,
+		Backend:  p.backend,
+		OwnerKey: owner,
+		Owner:    crypto.PubkeyToAddress(owner.PublicKey),
+		Abi:      &parsed,
+		Code:     d.Code,
+		Address:  d.Address,
+		Lenient:  true,
+	}, nil
+}
+
+//resolve replaces account names and contract aliases in the arguments with their addresses,
+//and splits "[a,b,...]" into a slice.
+func (p *session) resolve(args []string) []interface{} {
+	ret := make([]interface{}, len(args))
+	for i, a := range args {
+		if strings.HasPrefix(a, "[") && strings.HasSuffix(a, "]") {
+			elems := []string{}
+			if inner := strings.TrimSpace(a[1 : len(a)-1]); inner != "" {
+				for _, e := range strings.Split(inner, ",") {
+					elems = append(elems, strings.TrimSpace(e))
+				}
+			}
+			ret[i] = p.resolve(elems)
+			continue
+		}
+		ret[i] = p.address(a)
+	}
+	return ret
+}
+
+//address returns the address of the named account or contract in hex, or the name itself.
+func (p *session) address(name string) string {
+	if raw, ok := p.state.Keys[name]; ok == true {
+		if key, err := crypto.ToECDSA(raw); err == nil {
+			return crypto.PubkeyToAddress(key.PublicKey).Hex()
+		}
+	}
+	if d, ok := p.state.Contracts[name]; ok == true {
+		return d.Address.Hex()
+	}
+	return name
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Test to deploy, send and mine by commands, each on the chain replayed from the state file.
+func TestSessionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	buf := bytes.Buffer{}
+	e := &env{statePath: path, out: &printer{json: true, w: &buf}}
+
+	assert.NoError(t, runAccount(e, []string{"ecoFund", "wemix", "alice"}))
+	sess, err := openSession(path)
+	assert.NoError(t, err)
+	alice := sess.address("alice")
+	sess.backend.Close()
+
+	assert.NoError(t, runDeploy(e, []string{"-as", "token", wemixFile, "WemixToken", "ecoFund", "wemix"}))
+
+	buf.Reset()
+	assert.NoError(t, runSend(e, []string{"token", "transfer", "alice", "100"}))
+	receipt := struct {
+		Status uint64
+		Events []struct {
+			Event string
+			Args  map[string]string
+		}
+	}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &receipt))
+	assert.Equal(t, uint64(1), receipt.Status)
+	if assert.Len(t, receipt.Events, 1) {
+		assert.Equal(t, "Transfer", receipt.Events[0].Event)
+		assert.Equal(t, alice, receipt.Events[0].Args["to"])
+	}
+
+	buf.Reset()
+	assert.NoError(t, runMine(e, []string{"3"}))
+	mined := struct{ Block string }{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &mined))
+
+	//the accounts, the contract and the chain are loaded from the state file
+	sess, err = openSession(path)
+	assert.NoError(t, err)
+	defer sess.backend.Close()
+	assert.Equal(t, alice, sess.address("alice"))
+	assert.Equal(t, mined.Block, sess.backend.Blockchain().CurrentBlock().Number().String())
+
+	token, err := sess.contract("token")
+	assert.NoError(t, err)
+	values, err := token.LowCall("balanceOf", sess.resolve([]string{"alice"})...)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", formatValue(values[0]))
+
+	//saved again without a change
+	root := sess.backend.Blockchain().CurrentBlock().Root()
+	assert.NoError(t, sess.save())
+	again, err := openSession(path)
+	assert.NoError(t, err)
+	defer again.backend.Close()
+	assert.Equal(t, root, again.backend.Blockchain().CurrentBlock().Root())
+}
+
+//Test to load the chain with the timestamps of its blocks from the state file.
+func TestSessionTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	sess, err := openSession(path)
+	assert.NoError(t, err)
+	sess.backend.Commit() //make block
+	assert.NoError(t, sess.backend.AdjustTime(time.Hour))
+	sess.backend.Commit()
+	sess.backend.Commit()
+	head := sess.backend.Blockchain().CurrentBlock()
+	assert.NoError(t, sess.save())
+	sess.backend.Close()
+
+	sess, err = openSession(path)
+	assert.NoError(t, err)
+	defer sess.backend.Close()
+	loaded := sess.backend.Blockchain().CurrentBlock()
+	assert.Equal(t, head.Time(), loaded.Time())
+	assert.Equal(t, head.Hash(), loaded.Hash())
+}
+
+//Test to replace account names and contract aliases in arguments with their addresses.
+func TestSessionResolve(t *testing.T) {
+	sess, err := openSession(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	defer sess.backend.Close()
+	key, err := sess.key("alice")
+	assert.NoError(t, err)
+	alice := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	token := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	sess.state.Contracts["token"] = &deployment{Address: token}
+
+	assert.Equal(t, []interface{}{
+		alice,
+		token.Hex(),
+		"0x12",
+		"bob",
+		[]interface{}{alice, "2"},
+		[]interface{}{},
+		[]interface{}{[]interface{}{token.Hex()}},
+	}, sess.resolve([]string{"alice", "token", "0x12", "bob", "[alice, 2]", "[ ]", "[[token]]"}))
+	assert.Equal(t, []interface{}{}, sess.resolve(nil))
+}
@@ -0,0 +1,210 @@
+//Package conformance has test suites checking that a deployed contract behaves as a standard requires.
+package conformance
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//erc20 holds the token and the accounts of the ERC-20 suite.
+type erc20 struct {
+	token     *backend.Contract
+	holderKey *ecdsa.PrivateKey
+	holder    common.Address
+	spender   *ecdsa.PrivateKey
+	recipient *ecdsa.PrivateKey
+}
+
+//RunERC20 checks the EIP-20 behavior of the deployed token end to end: balances, allowances, the Transfer and Approval events,
+//and reverts of transfers to the zero address and beyond the balance or the allowance.
+//The holder needs at least 1000 tokens in the smallest unit. Its tokens move to new accounts, and the chain goes on.
+//increaseAllowance and decreaseAllowance are checked if the token has them.
+func RunERC20(t *testing.T, token *backend.Contract, holderKey *ecdsa.PrivateKey) {
+	keys := backend.NewKeyRing("conformance/" + t.Name())
+	p := &erc20{
+		token:     token,
+		holderKey: holderKey,
+		holder:    crypto.PubkeyToAddress(holderKey.PublicKey),
+		spender:   keys.Key("spender"),
+		recipient: keys.Key("recipient"),
+	}
+	if p.balanceOf(t, p.holder).Cmp(big.NewInt(1000)) < 0 {
+		t.Fatalf("holder %s has less than 1000 tokens", p.holder.Hex())
+	}
+	supply := p.call(t, "totalSupply")
+
+	t.Run("transfer", p.testTransfer)
+	t.Run("approve", p.testApprove)
+	t.Run("transferFrom", p.testTransferFrom)
+	if _, ok := token.Abi.Methods["increaseAllowance"]; ok == true {
+		t.Run("increaseAllowance", p.testIncreaseAllowance)
+	}
+	if _, ok := token.Abi.Methods["decreaseAllowance"]; ok == true {
+		t.Run("decreaseAllowance", p.testDecreaseAllowance)
+	}
+
+	equalBig(t, supply, p.call(t, "totalSupply"), "totalSupply")
+}
+
+func (p *erc20) testTransfer(t *testing.T) {
+	recipient := crypto.PubkeyToAddress(p.recipient.PublicKey)
+	holderBalance := p.balanceOf(t, p.holder)
+	recipientBalance := p.balanceOf(t, recipient)
+
+	receipt := p.success(t, p.holderKey, "transfer", recipient, big.NewInt(100))
+	p.checkEvent(t, receipt, "Transfer", p.holder, recipient, big.NewInt(100))
+	equalBig(t, new(big.Int).Sub(holderBalance, big.NewInt(100)), p.balanceOf(t, p.holder), "holder balance")
+	equalBig(t, new(big.Int).Add(recipientBalance, big.NewInt(100)), p.balanceOf(t, recipient), "recipient balance")
+
+	//transfers of 0 are normal transfers
+	receipt = p.success(t, p.holderKey, "transfer", recipient, big.NewInt(0))
+	p.checkEvent(t, receipt, "Transfer", p.holder, recipient, big.NewInt(0))
+
+	//beyond the balance
+	balance := p.balanceOf(t, recipient)
+	p.fail(t, p.recipient, "transfer", p.holder, new(big.Int).Add(balance, big.NewInt(1)))
+	equalBig(t, balance, p.balanceOf(t, recipient), "recipient balance after a failed transfer")
+
+	//to the zero address
+	p.fail(t, p.holderKey, "transfer", common.Address{}, big.NewInt(1))
+}
+
+func (p *erc20) testApprove(t *testing.T) {
+	spender := crypto.PubkeyToAddress(p.spender.PublicKey)
+
+	receipt := p.success(t, p.holderKey, "approve", spender, big.NewInt(300))
+	p.checkEvent(t, receipt, "Approval", p.holder, spender, big.NewInt(300))
+	equalBig(t, big.NewInt(300), p.call(t, "allowance", p.holder, spender), "allowance")
+
+	//approving again overwrites the allowance
+	receipt = p.success(t, p.holderKey, "approve", spender, big.NewInt(0))
+	p.checkEvent(t, receipt, "Approval", p.holder, spender, big.NewInt(0))
+	equalBig(t, big.NewInt(0), p.call(t, "allowance", p.holder, spender), "allowance")
+
+	//to the zero address
+	p.fail(t, p.holderKey, "approve", common.Address{}, big.NewInt(1))
+}
+
+func (p *erc20) testTransferFrom(t *testing.T) {
+	spender := crypto.PubkeyToAddress(p.spender.PublicKey)
+	recipient := crypto.PubkeyToAddress(p.recipient.PublicKey)
+	p.success(t, p.holderKey, "approve", spender, big.NewInt(300))
+	holderBalance := p.balanceOf(t, p.holder)
+	recipientBalance := p.balanceOf(t, recipient)
+
+	receipt := p.success(t, p.spender, "transferFrom", p.holder, recipient, big.NewInt(200))
+	p.checkEvent(t, receipt, "Transfer", p.holder, recipient, big.NewInt(200))
+	equalBig(t, new(big.Int).Sub(holderBalance, big.NewInt(200)), p.balanceOf(t, p.holder), "holder balance")
+	equalBig(t, new(big.Int).Add(recipientBalance, big.NewInt(200)), p.balanceOf(t, recipient), "recipient balance")
+	equalBig(t, big.NewInt(100), p.call(t, "allowance", p.holder, spender), "allowance")
+	equalBig(t, big.NewInt(0), p.balanceOf(t, spender), "spender balance")
+
+	//beyond the allowance
+	p.fail(t, p.spender, "transferFrom", p.holder, recipient, big.NewInt(101))
+	equalBig(t, big.NewInt(100), p.call(t, "allowance", p.holder, spender), "allowance after a failed transferFrom")
+
+	//beyond the balance
+	balance := p.balanceOf(t, recipient)
+	p.success(t, p.recipient, "approve", spender, new(big.Int).Add(balance, big.NewInt(1000)))
+	p.fail(t, p.spender, "transferFrom", recipient, p.holder, new(big.Int).Add(balance, big.NewInt(1)))
+	equalBig(t, balance, p.balanceOf(t, recipient), "recipient balance after a failed transferFrom")
+
+	//to the zero address
+	p.fail(t, p.spender, "transferFrom", p.holder, common.Address{}, big.NewInt(1))
+}
+
+func (p *erc20) testIncreaseAllowance(t *testing.T) {
+	spender := crypto.PubkeyToAddress(p.spender.PublicKey)
+	p.success(t, p.holderKey, "approve", spender, big.NewInt(100))
+
+	receipt := p.success(t, p.holderKey, "increaseAllowance", spender, big.NewInt(50))
+	p.checkEvent(t, receipt, "Approval", p.holder, spender, big.NewInt(150))
+	equalBig(t, big.NewInt(150), p.call(t, "allowance", p.holder, spender), "allowance")
+
+	p.fail(t, p.holderKey, "increaseAllowance", common.Address{}, big.NewInt(1))
+}
+
+func (p *erc20) testDecreaseAllowance(t *testing.T) {
+	spender := crypto.PubkeyToAddress(p.spender.PublicKey)
+	p.success(t, p.holderKey, "approve", spender, big.NewInt(150))
+
+	receipt := p.success(t, p.holderKey, "decreaseAllowance", spender, big.NewInt(150))
+	p.checkEvent(t, receipt, "Approval", p.holder, spender, big.NewInt(0))
+	equalBig(t, big.NewInt(0), p.call(t, "allowance", p.holder, spender), "allowance")
+
+	//below zero
+	p.fail(t, p.holderKey, "decreaseAllowance", spender, big.NewInt(1))
+	equalBig(t, big.NewInt(0), p.call(t, "allowance", p.holder, spender), "allowance after a failed decreaseAllowance")
+}
+
+//call invokes a view method returning a number.
+func (p *erc20) call(t *testing.T, method string, args ...interface{}) *big.Int {
+	ret, err := backend.CallAs[*big.Int](p.token, method, args...)
+	assert.NoError(t, err, method)
+	if ret == nil {
+		return new(big.Int)
+	}
+	return ret
+}
+
+func (p *erc20) balanceOf(t *testing.T, account common.Address) *big.Int {
+	return p.call(t, "balanceOf", account)
+}
+
+//success executes the method, and checks that the tx succeeds.
+func (p *erc20) success(t *testing.T, key *ecdsa.PrivateKey, method string, args ...interface{}) *types.Receipt {
+	receipt, err := p.token.Execute(key, method, args...)
+	if assert.NoError(t, err, method) == false {
+		return &types.Receipt{}
+	}
+	assert.Equal(t, uint64(1), receipt.Status, "status of %s tx", method)
+	return receipt
+}
+
+//fail executes the method, and checks that the tx reverts without events.
+func (p *erc20) fail(t *testing.T, key *ecdsa.PrivateKey, method string, args ...interface{}) {
+	receipt, err := p.token.Execute(key, method, args...)
+	if assert.NoError(t, err, method) == false {
+		return
+	}
+	assert.Equal(t, uint64(0), receipt.Status, "status of %s tx", method)
+	assert.Empty(t, receipt.Logs, "logs of %s tx", method)
+}
+
+//checkEvent checks that the receipt has the event of the token with the addresses and the value in the order of its inputs.
+func (p *erc20) checkEvent(t *testing.T, receipt *types.Receipt, event string, from, to common.Address, value *big.Int) {
+	logs := p.token.LogsOf(event, receipt)
+	if assert.Equal(t, 1, len(logs), "%s events", event) == false {
+		return
+	}
+	name, values, err := p.token.DecodeLog(logs[0])
+	if assert.NoError(t, err, event) == false {
+		return
+	}
+	assert.Equal(t, event, name)
+	inputs := p.token.Abi.Events[event].Inputs
+	if assert.Equal(t, 3, len(inputs), "inputs of %s event", event) == false {
+		return
+	}
+	assert.Equal(t, from, values[inputs[0].Name], "%s %s", event, inputs[0].Name)
+	assert.Equal(t, to, values[inputs[1].Name], "%s %s", event, inputs[1].Name)
+	got, _ := values[inputs[2].Name].(*big.Int)
+	equalBig(t, value, got, event+" "+inputs[2].Name)
+}
+
+//equalBig checks the numbers by value.
+func equalBig(t *testing.T, expected, actual *big.Int, what string) {
+	if actual == nil {
+		t.Errorf("%s: expected %s, but nil", what, expected)
+		return
+	}
+	assert.Equal(t, expected.String(), actual.String(), what)
+}
@@ -0,0 +1,308 @@
+package scenario
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//runner holds the chain of a running scenario.
+type runner struct {
+	s         *Scenario
+	r         Reporter
+	backend   *backend.Backend
+	keys      map[string]*ecdsa.PrivateKey
+	contracts map[string]*backend.Contract
+	first     string //alias of the first contract
+}
+
+//Run makes the accounts, deploys the contracts on a new backend and executes the steps.
+//Unmet expectations are reported to r, and an error is returned if the scenario can not go on.
+func (p *Scenario) Run(r Reporter) error {
+	run := &runner{
+		s:         p,
+		r:         r,
+		backend:   backend.NewBackend(),
+		keys:      map[string]*ecdsa.PrivateKey{},
+		contracts: map[string]*backend.Contract{},
+	}
+	defer run.backend.Close()
+
+	//keys are derived from the seed, so a failed run is reproduced with the same addresses
+	ring := backend.NewKeyRing("scenario/" + p.Name)
+	for _, name := range append([]string{"owner"}, p.Accounts...) {
+		run.keys[name] = ring.Key(name)
+	}
+
+	for _, d := range p.Contracts {
+		if err := run.deploy(d); err != nil {
+			return fmt.Errorf("deploy %s: %v", d.Name, err)
+		}
+	}
+
+	for i, s := range p.Steps {
+		label := fmt.Sprintf("step %d", i)
+		if s.Name != "" {
+			label += " (" + s.Name + ")"
+		}
+		if err := run.step(label, s); err != nil {
+			return fmt.Errorf("%s: %v", label, err)
+		}
+	}
+	return nil
+}
+
+func (p *runner) deploy(d Deployment) error {
+	file := d.File
+	if filepath.IsAbs(file) == false {
+		file = filepath.Join(p.s.dir, file)
+	}
+	contract, err := backend.Compile(file, d.Name)
+	if err != nil {
+		return err
+	}
+	contract.Backend = p.backend
+	contract.Lenient = true
+
+	key, err := p.key(d.From)
+	if err != nil {
+		return err
+	}
+	contract.OwnerKey, contract.Owner = key, crypto.PubkeyToAddress(key.PublicKey)
+
+	if err := contract.Deploy(p.resolve(d.Args)...); err != nil {
+		return err
+	}
+
+	alias := d.As
+	if alias == "" {
+		alias = d.Name
+	}
+	if _, ok := p.contracts[alias]; ok == true {
+		return fmt.Errorf("%s is deployed already", alias)
+	}
+	p.contracts[alias] = contract
+	if p.first == "" {
+		p.first = alias
+	}
+	p.r.Logf("ok > %s deployed at %s", alias, contract.Address.Hex())
+	return nil
+}
+
+func (p *runner) step(label string, s Step) error {
+	contract, err := p.contract(s.To)
+	if err != nil {
+		return err
+	}
+	key, err := p.key(s.From)
+	if err != nil {
+		return err
+	}
+	args := p.resolve(s.Args)
+	expect := s.Expect
+	if expect == nil {
+		expect = &Expect{}
+	}
+
+	switch {
+	case s.Send != "":
+		reason := ""
+		if expect.Reason != "" {
+			if reason, err = contract.RevertReason(crypto.PubkeyToAddress(key.PublicKey), s.Send, args...); err != nil {
+				return err
+			}
+		}
+		receipt, err := contract.Execute(key, s.Send, args...)
+		if err != nil {
+			return err
+		}
+		p.checkReceipt(label, contract, receipt, reason, expect)
+	case s.Call != "":
+		values, err := contract.LowCall(s.Call, args...)
+		if err != nil {
+			return err
+		}
+		if expect.Return != nil {
+			p.checkReturn(label, contract.Abi.Methods[s.Call].Outputs, values, expect.Return)
+		}
+	case s.Mine > 0:
+		for i := uint64(0); i < s.Mine; i++ {
+			p.backend.Commit() //make block
+		}
+	}
+
+	for account, expected := range expect.Balances {
+		balance, err := contract.LowCall("balanceOf", p.address(account))
+		if err != nil {
+			return err
+		}
+		p.checkValue(fmt.Sprintf("%s: balance of %s", label, account), contract.Abi.Methods["balanceOf"].Outputs[0].Type, balance[0], expected)
+	}
+	p.r.Logf("ok > %s", label)
+	return nil
+}
+
+//checkReceipt checks the status, the revert reason and the events, like expecedSuccess and expecedFail.
+func (p *runner) checkReceipt(label string, contract *backend.Contract, receipt *types.Receipt, reason string, expect *Expect) {
+	status := expect.Status
+	if status == "" && expect.Reason != "" {
+		status = "revert"
+	}
+	switch status {
+	case "", "success":
+		if receipt.Status != 1 {
+			p.r.Errorf("%s: expected success, got revert", label)
+		}
+	case "revert":
+		if receipt.Status != 0 {
+			p.r.Errorf("%s: expected revert, got success", label)
+		}
+	default:
+		p.r.Errorf("%s: unknown status: %s", label, status)
+	}
+	if expect.Reason != "" && reason != expect.Reason {
+		p.r.Errorf("%s: expected revert reason %q, got %q", label, expect.Reason, reason)
+	}
+
+	if expect.Events == nil {
+		return
+	}
+	logs := []*types.Log{}
+	for _, g := range receipt.Logs {
+		if g.Address == contract.Address {
+			logs = append(logs, g)
+		}
+	}
+	if len(logs) != len(expect.Events) {
+		p.r.Errorf("%s: expected %d events, got %d", label, len(expect.Events), len(logs))
+		return
+	}
+	for i, e := range expect.Events {
+		name, values, err := contract.DecodeLog(logs[i])
+		if err != nil {
+			p.r.Errorf("%s: event %d: %v", label, i, err)
+			continue
+		}
+		if name != e.Name {
+			p.r.Errorf("%s: event %d: expected %s, got %s", label, i, e.Name, name)
+			continue
+		}
+		for _, input := range contract.Abi.Events[name].Inputs {
+			if expected, ok := e.Args[input.Name]; ok == true {
+				p.checkValue(fmt.Sprintf("%s: %s.%s", label, name, input.Name), input.Type, values[input.Name], expected)
+			}
+		}
+	}
+}
+
+func (p *runner) checkReturn(label string, outputs abi.Arguments, values []interface{}, expected []interface{}) {
+	if len(values) != len(expected) {
+		p.r.Errorf("%s: expected %d return values, got %d", label, len(expected), len(values))
+		return
+	}
+	for i, output := range outputs {
+		p.checkValue(fmt.Sprintf("%s: return %d", label, i), output.Type, values[i], expected[i])
+	}
+}
+
+//checkValue converts the expected value to the ABI type, and compares it like checkVariable.
+func (p *runner) checkValue(label string, t abi.Type, got interface{}, expected interface{}) {
+	if h, ok := got.(common.Hash); ok == true && t.T != abi.FixedBytesTy {
+		//only the hash of an indexed dynamic value is in the topic
+		if fmt.Sprint(expected) != h.Hex() {
+			p.r.Errorf("%s: expected %v, got %s", label, expected, h.Hex())
+		}
+		return
+	}
+
+	want, err := backend.ConvertValue(t, p.resolveValue(expected))
+	if err != nil {
+		p.r.Errorf("%s: expected value: %v", label, err)
+		return
+	}
+	a, err := toBytes(want)
+	if err != nil {
+		p.r.Errorf("%s: %v", label, err)
+		return
+	}
+	b, err := toBytes(got)
+	if err != nil {
+		p.r.Errorf("%s: %v", label, err)
+		return
+	}
+	if bytes.Equal(a, b) == false {
+		p.r.Errorf("%s: expected %v, got %v", label, want, got)
+	}
+}
+
+//toBytes converts the value into a byte slice with gob.
+func toBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *runner) contract(alias string) (*backend.Contract, error) {
+	if alias == "" {
+		alias = p.first
+	}
+	c, ok := p.contracts[alias]
+	if ok == false {
+		return nil, fmt.Errorf("%s contract is not deployed", alias)
+	}
+	return c, nil
+}
+
+func (p *runner) key(account string) (*ecdsa.PrivateKey, error) {
+	if account == "" {
+		account = "owner"
+	}
+	key, ok := p.keys[account]
+	if ok == false {
+		return nil, fmt.Errorf("%s account is not here", account)
+	}
+	return key, nil
+}
+
+//address returns the address of the named account or contract in hex, or the name itself.
+func (p *runner) address(name string) string {
+	if key, ok := p.keys[name]; ok == true {
+		return crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+	if c, ok := p.contracts[name]; ok == true {
+		return c.Address.Hex()
+	}
+	return name
+}
+
+func (p *runner) resolve(args []interface{}) []interface{} {
+	ret := make([]interface{}, len(args))
+	for i, a := range args {
+		ret[i] = p.resolveValue(a)
+	}
+	return ret
+}
+
+//resolveValue replaces names with addresses and JSON numbers with strings.
+func (p *runner) resolveValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		return p.address(x)
+	case json.Number:
+		return x.String()
+	case []interface{}:
+		return p.resolve(x)
+	}
+	return v
+}
@@ -0,0 +1,125 @@
+//Package scenario runs declarative test scenarios written in YAML or JSON:
+//accounts, contract deployments, transactions, block advances and expectations.
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wemade-tree/contract-test/backend"
+	"gopkg.in/yaml.v2"
+)
+
+//Scenario is a test scenario file.
+type Scenario struct {
+	Name      string
+	Accounts  []string     //accounts made in addition to owner
+	Contracts []Deployment //contracts deployed in order
+	Steps     []Step
+
+	dir string //directory of the file, the base of the contract files
+}
+
+//Deployment deploys a contract by its alias.
+type Deployment struct {
+	As   string //alias, the contract name if empty
+	File string //solidity file, relative to the scenario file
+	Name string
+	From string //deployer, owner if empty
+	Args []interface{}
+}
+
+//Step is an action with expectations.
+//A step has only one of Send, Call and Mine, or none of them to only check Expect.
+type Step struct {
+	Name string //description
+	Send string //method to send a transaction to
+	Call string //method to call without a transaction
+	Mine uint64 //number of empty blocks to make
+	To   string //contract alias, the first contract if empty
+	From string //sender, owner if empty
+	Args []interface{}
+
+	Expect *Expect
+}
+
+//Expect holds the expected results of a step.
+//Values are converted to the ABI types like the arguments, and account or contract names to their addresses.
+type Expect struct {
+	Status   string                 //success or revert, success if empty
+	Reason   string                 //revert reason
+	Return   []interface{}          //outputs of Call
+	Events   []Event                //events emitted by Send, in order
+	Balances map[string]interface{} //balanceOf of the contract by account
+}
+
+//Event is an expected event, Args may hold only some of the inputs.
+type Event struct {
+	Name string
+	Args map[string]interface{}
+}
+
+//Reporter receives the results of a scenario, *testing.T is one.
+type Reporter interface {
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+//Load reads a scenario from a JSON file if the extension is .json, or from a YAML file.
+func Load(path string) (*Scenario, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Scenario{}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		err = d.Decode(r)
+	} else {
+		err = yaml.UnmarshalStrict(b, r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if r.Name == "" {
+		r.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	r.dir = filepath.Dir(path)
+	return r, nil
+}
+
+//RunTests runs the scenario files matching the pattern, each as a subtest.
+func RunTests(t *testing.T, pattern string) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no scenario file matches %s", pattern)
+	}
+	for _, f := range files {
+		s, err := Load(f)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		t.Run(s.Name, func(t *testing.T) {
+			defer func() {
+				if t.Failed() {
+					t.Logf("reproduce with %s=%s", backend.SeedEnv, backend.Seed())
+				}
+			}()
+			if err := s.Run(t); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
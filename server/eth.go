@@ -0,0 +1,371 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//callArgs is the transaction object of eth_call and eth_estimateGas.
+type callArgs struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+//msg returns the call message of the arguments.
+func (p *callArgs) msg() ethereum.CallMsg {
+	r := ethereum.CallMsg{To: p.To}
+	if p.From != nil {
+		r.From = *p.From
+	}
+	if p.Gas != nil {
+		r.Gas = uint64(*p.Gas)
+	}
+	if p.GasPrice != nil {
+		r.GasPrice = p.GasPrice.ToInt()
+	}
+	if p.Value != nil {
+		r.Value = p.Value.ToInt()
+	}
+	if p.Input != nil {
+		r.Data = *p.Input
+	} else if p.Data != nil {
+		r.Data = *p.Data
+	}
+	return r
+}
+
+//ethAPI serves the eth namespace.
+type ethAPI struct {
+	s *Server
+}
+
+//block returns the block by number or hash, the latest one if nil.
+//The pending block is the latest one, since transactions are mined at once.
+func block(b *backend.Backend, bnh *rpc.BlockNumberOrHash) (*types.Block, error) {
+	chain := b.Blockchain()
+	if bnh == nil {
+		return chain.CurrentBlock(), nil
+	}
+	if hash, ok := bnh.Hash(); ok == true {
+		r := chain.GetBlockByHash(hash)
+		if r == nil {
+			return nil, fmt.Errorf("block %s is not here", hash.Hex())
+		}
+		return r, nil
+	}
+	n, _ := bnh.Number()
+	if n == rpc.LatestBlockNumber || n == rpc.PendingBlockNumber {
+		return chain.CurrentBlock(), nil
+	}
+	r := chain.GetBlockByNumber(uint64(n.Int64()))
+	if r == nil {
+		return nil, fmt.Errorf("block %d is not here", n.Int64())
+	}
+	return r, nil
+}
+
+//stateAt returns the state of the block by number or hash.
+func stateAt(b *backend.Backend, bnh *rpc.BlockNumberOrHash) (*state.StateDB, error) {
+	blk, err := block(b, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return b.Blockchain().StateAt(blk.Root())
+}
+
+func (p *ethAPI) ChainId() *hexutil.Big {
+	b, _ := p.s.chain()
+	return (*hexutil.Big)(b.Blockchain().Config().ChainID)
+}
+
+func (p *ethAPI) BlockNumber() hexutil.Uint64 {
+	b, _ := p.s.chain()
+	return hexutil.Uint64(b.Blockchain().CurrentBlock().NumberU64())
+}
+
+//GasPrice is zero, since the accounts of the simulated backend have no ether.
+func (p *ethAPI) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int))
+}
+
+//Accounts is empty, since the server keeps no keys. Transactions are signed by the clients.
+func (p *ethAPI) Accounts() []common.Address {
+	return []common.Address{}
+}
+
+func (p *ethAPI) GetBalance(address common.Address, bnh *rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	b, _ := p.s.chain()
+	st, err := stateAt(b, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(st.GetBalance(address)), nil
+}
+
+func (p *ethAPI) GetCode(address common.Address, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	b, _ := p.s.chain()
+	st, err := stateAt(b, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetCode(address), nil
+}
+
+func (p *ethAPI) GetStorageAt(address common.Address, key common.Hash, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	b, _ := p.s.chain()
+	st, err := stateAt(b, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetState(address, key).Bytes(), nil
+}
+
+func (p *ethAPI) GetTransactionCount(address common.Address, bnh *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	b, _ := p.s.chain()
+	st, err := stateAt(b, bnh)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(st.GetNonce(address)), nil
+}
+
+//revertError is the JSON-RPC error of a reverted call, with the revert data like geth's.
+type revertError struct {
+	reason string
+	data   hexutil.Bytes
+}
+
+func (p *revertError) Error() string {
+	return "execution reverted: " + p.reason
+}
+
+//ErrorCode returns the code of a reverted call used by geth.
+func (p *revertError) ErrorCode() int {
+	return 3
+}
+
+//ErrorData returns the revert data.
+func (p *revertError) ErrorData() interface{} {
+	return p.data
+}
+
+//Call executes the call on the latest block. The simulated backend can not call on older blocks.
+//The simulated backend returns the output of a reverted call without an error,
+//so a call reverted with a reason returns revertError.
+func (p *ethAPI) Call(ctx context.Context, args callArgs, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	b, _ := p.s.chain()
+	blk, err := block(b, bnh)
+	if err != nil {
+		return nil, err
+	}
+	if blk.Hash() != b.Blockchain().CurrentBlock().Hash() {
+		return nil, fmt.Errorf("eth_call is supported only on the latest block")
+	}
+	output, err := b.CallContract(ctx, args.msg(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if reason, ok := backend.UnpackRevert(output); ok == true {
+		return nil, &revertError{reason: reason, data: output}
+	}
+	return output, nil
+}
+
+func (p *ethAPI) EstimateGas(ctx context.Context, args callArgs) (hexutil.Uint64, error) {
+	b, _ := p.s.chain()
+	gas, err := b.EstimateGas(ctx, args.msg())
+	return hexutil.Uint64(gas), err
+}
+
+//SendRawTransaction sends the signed transaction and mines it in a new block.
+func (p *ethAPI) SendRawTransaction(ctx context.Context, raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return common.Hash{}, err
+	}
+
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	defer backend.LockBackend(p.s.backend)() //shared with Contracts on the backend
+	if err := send(ctx, p.s.backend, tx); err != nil {
+		return common.Hash{}, err
+	}
+	p.s.backend.Commit() //make block
+	return tx.Hash(), nil
+}
+
+//send sends the transaction to the backend, which panics on an invalid nonce or transaction.
+func send(ctx context.Context, b *backend.Backend, tx *types.Transaction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return b.SendTransaction(ctx, tx)
+}
+
+func (p *ethAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	b, _ := p.s.chain()
+	tx, _, err := b.TransactionByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	receipt, err := b.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return marshalTx(b, tx, receipt), nil
+}
+
+func (p *ethAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	b, _ := p.s.chain()
+	receipt, err := b.TransactionReceipt(ctx, hash)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	tx, _, err := b.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return marshalReceipt(b, tx, receipt), nil
+}
+
+func (p *ethAPI) GetBlockByNumber(n rpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	b, _ := p.s.chain()
+	blk, err := block(b, &rpc.BlockNumberOrHash{BlockNumber: &n})
+	if err != nil {
+		return nil, nil
+	}
+	return marshalBlock(b, blk, full), nil
+}
+
+func (p *ethAPI) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	b, _ := p.s.chain()
+	blk := b.Blockchain().GetBlockByHash(hash)
+	if blk == nil {
+		return nil, nil
+	}
+	return marshalBlock(b, blk, full), nil
+}
+
+func (p *ethAPI) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
+	b, _ := p.s.chain()
+	logs, err := b.FilterLogs(ctx, ethereum.FilterQuery(crit))
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return logs, nil
+}
+
+//NewHeads is the newHeads subscription of eth_subscribe.
+func (p *ethAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return p.s.subscribe(ctx, func(b *backend.Backend, notify func(interface{})) (ethereum.Subscription, error) {
+		heads := make(chan *types.Header)
+		sub, err := b.SubscribeNewHead(context.Background(), heads)
+		if err != nil {
+			return nil, err
+		}
+		go forward(sub, heads, notify)
+		return sub, nil
+	})
+}
+
+//Logs is the logs subscription of eth_subscribe.
+func (p *ethAPI) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error) {
+	return p.s.subscribe(ctx, func(b *backend.Backend, notify func(interface{})) (ethereum.Subscription, error) {
+		logs := make(chan types.Log)
+		sub, err := b.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery(crit), logs)
+		if err != nil {
+			return nil, err
+		}
+		go forward(sub, logs, notify)
+		return sub, nil
+	})
+}
+
+//forward notifies the values received from the channel until the subscription ends.
+func forward[T any](sub ethereum.Subscription, ch <-chan T, notify func(interface{})) {
+	for {
+		select {
+		case v := <-ch:
+			notify(v)
+		case <-sub.Err():
+			return
+		}
+	}
+}
+
+//subscribe creates a subscription of the client, which is subscribed again to the new backend after evm_revert.
+func (p *Server) subscribe(ctx context.Context, open func(b *backend.Backend, notify func(interface{})) (ethereum.Subscription, error)) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if ok == false {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	notify := func(v interface{}) {
+		notifier.Notify(rpcSub.ID, v)
+	}
+
+	b, reset := p.chain()
+	sub, err := open(b, notify)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case <-reset:
+				sub.Unsubscribe()
+				b, reset = p.chain()
+				if sub, err = open(b, notify); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				sub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				sub.Unsubscribe()
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+//netAPI serves the net namespace.
+type netAPI struct {
+	s *Server
+}
+
+func (p *netAPI) Version() string {
+	b, _ := p.s.chain()
+	return b.Blockchain().Config().ChainID.String()
+}
+
+//web3API serves the web3 namespace.
+type web3API struct{}
+
+func (p *web3API) ClientVersion() string {
+	return "contract-test"
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//evmAPI serves the evm namespace of development methods, named like ganache's.
+type evmAPI struct {
+	s *Server
+}
+
+//Mine makes the number of empty blocks, one if nil, and returns the block number.
+func (p *evmAPI) Mine(blocks *hexutil.Uint64) hexutil.Uint64 {
+	n := uint64(1)
+	if blocks != nil {
+		n = uint64(*blocks)
+	}
+
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	defer backend.LockBackend(p.s.backend)()
+	for i := uint64(0); i < n; i++ {
+		p.s.backend.Commit() //make block
+	}
+	return hexutil.Uint64(p.s.backend.Blockchain().CurrentBlock().NumberU64())
+}
+
+//IncreaseTime moves the time of the next block forward by the seconds.
+func (p *evmAPI) IncreaseTime(seconds hexutil.Uint64) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	defer backend.LockBackend(p.s.backend)()
+	return p.s.backend.AdjustTime(time.Duration(seconds) * time.Second)
+}
+
+//Snapshot saves the chain and returns the snapshot id.
+func (p *evmAPI) Snapshot() (hexutil.Uint64, error) {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	journal, err := backend.RecordJournal(p.s.backend)
+	if err != nil {
+		return 0, err
+	}
+	p.s.snapshots = append(p.s.snapshots, journal)
+	return hexutil.Uint64(len(p.s.snapshots) - 1), nil
+}
+
+//Revert makes the chain of the snapshot again on a new backend, and removes the snapshot and the later ones.
+//It returns false if the snapshot is not here.
+func (p *evmAPI) Revert(id hexutil.Uint64) (bool, error) {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	if int(id) >= len(p.s.snapshots) {
+		return false, nil
+	}
+
+	b := backend.NewBackend()
+	if err := p.s.snapshots[id].Replay(b); err != nil {
+		b.Close()
+		return false, fmt.Errorf("snapshot %d: %v", id, err)
+	}
+	p.s.backend.Close()
+	p.s.backend = b
+	close(p.s.reset)
+	p.s.reset = make(chan struct{})
+	p.s.snapshots = p.s.snapshots[:id]
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//signer returns the signer of the backend's chain, which also accepts transactions without chain id.
+func signer(b *backend.Backend) types.Signer {
+	return types.NewEIP155Signer(b.Blockchain().Config().ChainID)
+}
+
+//marshalBlock returns the JSON-RPC fields of the block, with the transactions in full or by hash.
+func marshalBlock(b *backend.Backend, block *types.Block, full bool) map[string]interface{} {
+	head := block.Header()
+	r := map[string]interface{}{
+		"number":           (*hexutil.Big)(head.Number),
+		"hash":             block.Hash(),
+		"parentHash":       head.ParentHash,
+		"nonce":            head.Nonce,
+		"mixHash":          head.MixDigest,
+		"sha3Uncles":       head.UncleHash,
+		"logsBloom":        head.Bloom,
+		"stateRoot":        head.Root,
+		"miner":            head.Coinbase,
+		"difficulty":       (*hexutil.Big)(head.Difficulty),
+		"totalDifficulty":  (*hexutil.Big)(b.Blockchain().GetTd(block.Hash(), block.NumberU64())),
+		"extraData":        hexutil.Bytes(head.Extra),
+		"size":             hexutil.Uint64(block.Size()),
+		"gasLimit":         hexutil.Uint64(head.GasLimit),
+		"gasUsed":          hexutil.Uint64(head.GasUsed),
+		"timestamp":        hexutil.Uint64(head.Time),
+		"transactionsRoot": head.TxHash,
+		"receiptsRoot":     head.ReceiptHash,
+	}
+
+	txs := []interface{}{}
+	for i, tx := range block.Transactions() {
+		if full == false {
+			txs = append(txs, tx.Hash())
+			continue
+		}
+		fields := marshalTx(b, tx, nil)
+		fields["blockHash"] = block.Hash()
+		fields["blockNumber"] = (*hexutil.Big)(head.Number)
+		fields["transactionIndex"] = hexutil.Uint64(i)
+		txs = append(txs, fields)
+	}
+	r["transactions"] = txs
+
+	uncles := []common.Hash{}
+	for _, u := range block.Uncles() {
+		uncles = append(uncles, u.Hash())
+	}
+	r["uncles"] = uncles
+	return r
+}
+
+//marshalTx returns the JSON-RPC fields of the transaction, with its block taken from the receipt if any.
+func marshalTx(b *backend.Backend, tx *types.Transaction, receipt *types.Receipt) map[string]interface{} {
+	from, _ := backend.Sender(signer(b), tx)
+	v, rr, s := tx.RawSignatureValues()
+	r := map[string]interface{}{
+		"hash":             tx.Hash(),
+		"nonce":            hexutil.Uint64(tx.Nonce()),
+		"blockHash":        nil,
+		"blockNumber":      nil,
+		"transactionIndex": nil,
+		"from":             from,
+		"to":               tx.To(),
+		"value":            (*hexutil.Big)(tx.Value()),
+		"gas":              hexutil.Uint64(tx.Gas()),
+		"gasPrice":         (*hexutil.Big)(tx.GasPrice()),
+		"input":            hexutil.Bytes(tx.Data()),
+		"v":                (*hexutil.Big)(v),
+		"r":                (*hexutil.Big)(rr),
+		"s":                (*hexutil.Big)(s),
+	}
+	if receipt != nil {
+		r["blockHash"] = receipt.BlockHash
+		r["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+		r["transactionIndex"] = hexutil.Uint64(receipt.TransactionIndex)
+	}
+	return r
+}
+
+//marshalReceipt returns the JSON-RPC fields of the receipt of the transaction.
+func marshalReceipt(b *backend.Backend, tx *types.Transaction, receipt *types.Receipt) map[string]interface{} {
+	from, _ := backend.Sender(signer(b), tx)
+	logs := receipt.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	r := map[string]interface{}{
+		"transactionHash":   tx.Hash(),
+		"transactionIndex":  hexutil.Uint64(receipt.TransactionIndex),
+		"blockHash":         receipt.BlockHash,
+		"blockNumber":       (*hexutil.Big)(receipt.BlockNumber),
+		"from":              from,
+		"to":                tx.To(),
+		"gasUsed":           hexutil.Uint64(receipt.GasUsed),
+		"cumulativeGasUsed": hexutil.Uint64(receipt.CumulativeGasUsed),
+		"contractAddress":   nil,
+		"logs":              logs,
+		"logsBloom":         receipt.Bloom,
+		"status":            hexutil.Uint64(receipt.Status),
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		r["contractAddress"] = receipt.ContractAddress
+	}
+	return r
+}
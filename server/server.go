@@ -0,0 +1,92 @@
+//Package server serves a simulated backend as a local JSON-RPC endpoint over HTTP and WebSocket,
+//so that wallets and scripts like web3.js, ethers and cast can use the chain of the Go tests.
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Server serves eth_* methods of a simulated backend, and evm_* methods for mining and snapshots.
+//Every transaction received is mined in a new block at once.
+type Server struct {
+	mu        sync.RWMutex
+	backend   *backend.Backend
+	reset     chan struct{}      //closed when the backend is replaced by evm_revert
+	snapshots []*backend.Journal //journals by snapshot id
+
+	rpc *rpc.Server
+	ws  http.Handler
+}
+
+//New returns a server of the backend.
+func New(b *backend.Backend) (*Server, error) {
+	r := &Server{
+		backend: b,
+		reset:   make(chan struct{}),
+		rpc:     rpc.NewServer(),
+	}
+	for namespace, api := range map[string]interface{}{
+		"eth":  &ethAPI{s: r},
+		"evm":  &evmAPI{s: r},
+		"net":  &netAPI{s: r},
+		"web3": &web3API{},
+	} {
+		if err := r.rpc.RegisterName(namespace, api); err != nil {
+			return nil, fmt.Errorf("register %s: %v", namespace, err)
+		}
+	}
+	r.ws = r.rpc.WebsocketHandler([]string{"*"})
+	return r, nil
+}
+
+//Backend returns the backend being served. It is a new one after evm_revert.
+func (p *Server) Backend() *backend.Backend {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.backend
+}
+
+//chain returns the backend and the channel closed when it is replaced.
+func (p *Server) chain() (*backend.Backend, <-chan struct{}) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.backend, p.reset
+}
+
+//ServeHTTP serves JSON-RPC requests over HTTP, and over WebSocket when the connection is upgraded.
+//Any origin is allowed, since the server is only for local development.
+func (p *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		p.ws.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	p.rpc.ServeHTTP(w, r)
+}
+
+//ListenAndServe listens on the address, like 127.0.0.1:8545, and serves until an error occurs.
+func (p *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return http.Serve(l, p)
+}
+
+//Close stops the subscriptions and the JSON-RPC server. The backend is not closed.
+func (p *Server) Close() {
+	p.rpc.Stop()
+}
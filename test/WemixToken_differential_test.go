@@ -0,0 +1,126 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to replay the same call sequence on two compilations of WemixToken and find no difference.
+func TestWemixDifferential(t *testing.T) {
+	oldWemix, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	newWemix, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+
+	ecoFundKey := newKey(t)
+	wemixKey := newKey(t)
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+
+	unitStaking := toBig(t, "2000000000000000000000000")
+	d := &backend.Differential{
+		Old: oldWemix,
+		New: newWemix,
+		DeployArgs: []interface{}{
+			crypto.PubkeyToAddress(ecoFundKey.PublicKey),
+			crypto.PubkeyToAddress(wemixKey.PublicKey),
+		},
+		Actors: []*ecdsa.PrivateKey{oldWemix.OwnerKey, partnerKey},
+		Probes: []backend.Probe{
+			{Method: "totalSupply"},
+			{Method: "balanceOf", Args: []interface{}{oldWemix.Owner}},
+			{Method: "balanceOf", Args: []interface{}{partner}},
+			{Method: "partnersNumber"},
+		},
+	}
+
+	diffs, err := d.Run([]backend.Step{
+		{Actor: 0, Method: "transfer", Args: []interface{}{partner, unitStaking}},
+		{Actor: 0, Method: "addAllowedPartner", Args: []interface{}{partner}},
+		{Actor: 1, Method: "stake", Args: []interface{}{big.NewInt(0)}, Blocks: 60},
+		{Actor: 1, Method: "mint"},
+		{Actor: 1, Method: "withdraw", Args: []interface{}{big.NewInt(1)}},
+		{Actor: 0, Method: "balanceOf", Args: []interface{}{partner}},
+	})
+	assert.NoError(t, err)
+	for _, diff := range diffs {
+		t.Error(diff)
+	}
+	t.Log("ok > no difference")
+}
+
+//Test to replay a call sequence on WemixToken and a copy withdrawing without the waiting blocks,
+//and find the withdrawal in the differences.
+func TestWemixDifferentialChanged(t *testing.T) {
+	t.Parallel()
+	oldWemix, err := backend.Compile("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	changed := changedWemix(t,
+		`require(_p.blockStaking + _p.blockWaitingWithdrawal <= block.number, "WemixToken: _p.blockStaking + _p.blockWaitingWithdrawal is higher than block.number");`, "")
+	newWemix, err := backend.Compile(changed, "WemixToken")
+	assert.NoError(t, err)
+
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+	d := &backend.Differential{
+		Old: oldWemix,
+		New: newWemix,
+		DeployArgs: []interface{}{
+			crypto.PubkeyToAddress(newKey(t).PublicKey),
+			crypto.PubkeyToAddress(newKey(t).PublicKey),
+		},
+		Actors: []*ecdsa.PrivateKey{oldWemix.OwnerKey, partnerKey},
+		Probes: []backend.Probe{
+			{Method: "balanceOf", Args: []interface{}{partner}},
+			{Method: "partnersNumber"},
+		},
+	}
+
+	unitStaking := toBig(t, "2000000000000000000000000")
+	diffs, err := d.Run([]backend.Step{
+		{Actor: 0, Method: "transfer", Args: []interface{}{partner, unitStaking}},
+		{Actor: 0, Method: "addAllowedPartner", Args: []interface{}{partner}},
+		{Actor: 1, Method: "stake", Args: []interface{}{big.NewInt(0)}},
+		{Actor: 1, Method: "withdraw", Args: []interface{}{big.NewInt(1)}},
+	})
+	assert.NoError(t, err)
+
+	found := map[string]backend.Difference{}
+	for _, diff := range diffs {
+		t.Log(diff)
+		assert.Equal(t, 3, diff.Step, "only the withdrawal differs")
+		found[diff.Kind+" "+diff.What] = diff
+	}
+	assert.Len(t, found, 5)
+
+	//the old one reverts before the waiting blocks, the new one withdraws
+	status, ok := found["status withdraw(1)"]
+	assert.True(t, ok)
+	assert.Equal(t, "0", status.Old)
+	assert.Equal(t, "1", status.New)
+	ret, ok := found["return withdraw(1)"]
+	assert.True(t, ok)
+	assert.NotEmpty(t, ret.Old)
+	assert.Empty(t, ret.New)
+	event, ok := found["event withdraw(1)"]
+	assert.True(t, ok)
+	assert.Empty(t, event.Old)
+	assert.Contains(t, event.New, "Withdrawal")
+
+	balance, ok := found["state "+backend.Probe{Method: "balanceOf", Args: []interface{}{partner}}.String()]
+	assert.True(t, ok)
+	assert.Equal(t, "[0] <nil>", balance.Old)
+	assert.Equal(t, fmt.Sprint([]interface{}{unitStaking}, nil), balance.New)
+	number, ok := found["state partnersNumber()"]
+	assert.True(t, ok)
+	assert.Equal(t, "[1] <nil>", number.Old)
+	assert.Equal(t, "[0] <nil>", number.New)
+	t.Log("ok > changed withdrawal is found")
+}
@@ -0,0 +1,358 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//wemixInvariants returns the properties of WemixToken that must hold after any call sequence.
+//holders must contain every address that can receive tokens.
+func wemixInvariants(holders []common.Address) []backend.Invariant {
+	return []backend.Invariant{
+		{
+			Name: "totalSupply equals the sum of balances",
+			Check: func(contract *backend.Contract) error {
+				sum := new(big.Int)
+				for _, h := range append(holders, contract.Address) {
+					balance := (*big.Int)(nil)
+					if err := contract.Call(&balance, "balanceOf", h); err != nil {
+						return err
+					}
+					sum.Add(sum, balance)
+				}
+				totalSupply := (*big.Int)(nil)
+				if err := contract.Call(&totalSupply, "totalSupply"); err != nil {
+					return err
+				}
+				if totalSupply.Cmp(sum) != 0 {
+					return fmt.Errorf("totalSupply: %v, sum of balances: %v", totalSupply, sum)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "contract balance equals the sum of BalanceStaking",
+			Check: func(contract *backend.Contract) error {
+				stakes := typePartnerSlice{}
+				if err := stakes.load(contract); err != nil {
+					return err
+				}
+				sum := new(big.Int)
+				for _, s := range stakes {
+					sum.Add(sum, s.BalanceStaking)
+				}
+				balance := (*big.Int)(nil)
+				if err := contract.Call(&balance, "balanceOf", contract.Address); err != nil {
+					return err
+				}
+				if balance.Cmp(sum) != 0 {
+					return fmt.Errorf("contract balance: %v, sum of BalanceStaking: %v", balance, sum)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "partners match the stakes not withdrawn",
+			Check: func(contract *backend.Contract) error {
+				expected, err := liveStakes(contract)
+				if err != nil {
+					return err
+				}
+				partnersNumber := (*big.Int)(nil)
+				if err := contract.Call(&partnersNumber, "partnersNumber"); err != nil {
+					return err
+				}
+				if partnersNumber.Cmp(big.NewInt(int64(len(expected)))) != 0 {
+					return fmt.Errorf("partnersNumber: %v, stakes not withdrawn: %d", partnersNumber, len(expected))
+				}
+				for serial, e := range expected {
+					bySerial := typePartner{}
+					if err := contract.Call(&bySerial, "partnerBySerial", new(big.Int).SetUint64(serial)); err != nil {
+						return err
+					}
+					if bySerial.Serial.Uint64() != serial {
+						return fmt.Errorf("partnerBySerial(%d) returns serial %v", serial, bySerial.Serial)
+					}
+					if bySerial.Partner != e.Partner || bySerial.Payer != e.Payer {
+						return fmt.Errorf("serial %d: partner %s and payer %s, staked by %s for %s",
+							serial, bySerial.Partner.Hex(), bySerial.Payer.Hex(), e.Payer.Hex(), e.Partner.Hex())
+					}
+					if bySerial.BalanceStaking.Cmp(e.BalanceStaking) != 0 {
+						return fmt.Errorf("serial %d: BalanceStaking %v, staked %v", serial, bySerial.BalanceStaking, e.BalanceStaking)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+//liveStakes returns the stakes not withdrawn by serial, built from the Staked and Withdrawal events of the contract.
+//The amount of a stake is the token transferred to the contract in the staking tx.
+func liveStakes(contract *backend.Contract) (map[uint64]*typePartner, error) {
+	logs, err := contract.Backend.FilterLogs(context.Background(), ethereum.FilterQuery{Addresses: []common.Address{contract.Address}})
+	if err != nil {
+		return nil, err
+	}
+
+	type staking struct {
+		Partner common.Address
+		Payer   common.Address
+		Serial  *big.Int
+	}
+	type transfer struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+	stakes := map[uint64]*typePartner{}
+	deposits := map[common.Hash]*big.Int{} //token transferred to the contract by tx
+	for _, g := range logs {
+		switch g.Topics[0] {
+		case contract.Abi.Events["Transfer"].Id():
+			e := transfer{}
+			if err := contract.UnpackLog(&e, "Transfer", g); err != nil {
+				return nil, err
+			}
+			if e.To == contract.Address {
+				deposits[g.TxHash] = e.Value
+			}
+		case contract.Abi.Events["Staked"].Id():
+			e := staking{}
+			if err := contract.UnpackLog(&e, "Staked", g); err != nil {
+				return nil, err
+			}
+			amount, ok := deposits[g.TxHash]
+			if ok == false {
+				return nil, fmt.Errorf("serial %v is staked without a transfer to the contract", e.Serial)
+			}
+			stakes[e.Serial.Uint64()] = &typePartner{Serial: e.Serial, Partner: e.Partner, Payer: e.Payer, BalanceStaking: amount}
+		case contract.Abi.Events["Withdrawal"].Id():
+			e := staking{}
+			if err := contract.UnpackLog(&e, "Withdrawal", g); err != nil {
+				return nil, err
+			}
+			if _, ok := stakes[e.Serial.Uint64()]; ok == false {
+				return nil, fmt.Errorf("serial %v is withdrawn without a stake", e.Serial)
+			}
+			delete(stakes, e.Serial.Uint64())
+		}
+	}
+	return stakes, nil
+}
+
+//Test to execute random call sequences and check the WemixToken invariants after each step.
+func TestWemixInvariant(t *testing.T) {
+	contract, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	defer contract.Backend.Close()
+
+	ecoFundKey := newKey(t)
+	wemixKey := newKey(t)
+	ecoFund := crypto.PubkeyToAddress(ecoFundKey.PublicKey)
+	wemix := crypto.PubkeyToAddress(wemixKey.PublicKey)
+
+	actors := []*ecdsa.PrivateKey{contract.OwnerKey}
+	holders := []common.Address{contract.Owner, ecoFund, wemix}
+	for i := 0; i < 3; i++ {
+		key := newKey(t)
+		actors = append(actors, key)
+		holders = append(holders, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	unitStaking := toBig(t, "2000000000000000000000000")
+	amount := func(r *rand.Rand) *big.Int {
+		return new(big.Int).Mul(unitStaking, big.NewInt(r.Int63n(3)))
+	}
+
+	test := &backend.InvariantTest{
+		Contract:   contract,
+		DeployArgs: []interface{}{ecoFund, wemix},
+		Setup: func(c *backend.Contract) error {
+			//shorten the waiting period so that withdrawals happen in a sequence
+			if err := executeOk(c, nil, "change_minBlockWaitingWithdrawal", big.NewInt(10)); err != nil {
+				return err
+			}
+			for _, key := range actors[1:] {
+				if err := executeOk(c, nil, "transfer", crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Mul(unitStaking, big.NewInt(2))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Actors:  actors,
+		Methods: []string{"transfer", "addAllowedPartner", "removeAllowedPartner", "stake", "stakeDelegated", "withdraw", "mint"},
+		Args: map[string]backend.ArgsFunc{
+			"transfer": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{actors[r.Intn(len(actors))], amount(r)}
+			},
+			"stake": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{big.NewInt(r.Int63n(20))}
+			},
+			"stakeDelegated": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{actors[r.Intn(len(actors))], big.NewInt(r.Int63n(20))}
+			},
+			"withdraw": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{big.NewInt(r.Int63n(10))}
+			},
+		},
+		Invariants: wemixInvariants(holders),
+		Runs:       5,
+		Depth:      30,
+		MaxBlocks:  20,
+		Seed:       1,
+	}
+
+	failure, err := test.Run()
+	assert.NoError(t, err)
+	if failure != nil {
+		t.Fatal(failure)
+	}
+	t.Log("ok > invariants hold")
+}
+
+//checkWemixInvariants stakes two partners on the WemixToken of the source file, withdraws the first one,
+//and returns the names of the violated invariants.
+func checkWemixInvariants(t *testing.T, file string) []string {
+	contract, err := backend.NewContract(file, "WemixToken")
+	assert.NoError(t, err)
+	defer contract.Backend.Close()
+
+	ecoFund := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	wemix := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	assert.NoError(t, contract.Deploy(ecoFund, wemix))
+	expecedSuccess(t, contract, nil, "change_minBlockWaitingWithdrawal", new(big.Int))
+
+	holders := []common.Address{contract.Owner, ecoFund, wemix}
+	partnerKeys := []*ecdsa.PrivateKey{newKey(t), newKey(t)}
+	for _, key := range partnerKeys {
+		partner := crypto.PubkeyToAddress(key.PublicKey)
+		holders = append(holders, partner)
+		expecedSuccess(t, contract, nil, "transfer", partner, toBig(t, "2000000000000000000000000"))
+		expecedSuccess(t, contract, nil, "addAllowedPartner", partner)
+		expecedSuccess(t, contract, key, "stake", new(big.Int))
+	}
+	//the second partner is moved to the index of the first one
+	expecedSuccess(t, contract, partnerKeys[0], "withdraw", big.NewInt(1))
+
+	violated := []string{}
+	for _, invariant := range wemixInvariants(holders) {
+		if err := invariant.Check(contract); err != nil {
+			t.Logf("%s: %v", invariant.Name, err)
+			violated = append(violated, invariant.Name)
+		}
+	}
+	return violated
+}
+
+//Test that the invariants hold after a withdrawal, and find a withdrawal which does not move the partner of the last stake.
+func TestWemixInvariantWithdraw(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, checkWemixInvariants(t, "../contracts/WemixToken.sol"))
+
+	broken := changedWemix(t, "allPartners[_subIndex] = _lastP;",
+		"allPartners[_subIndex].serial = _lastP.serial; allPartners[_subIndex].payer = _lastP.payer;")
+	assert.Equal(t, []string{"partners match the stakes not withdrawn"}, checkWemixInvariants(t, broken))
+	t.Log("ok > broken withdraw is found")
+}
+
+//Test to find the broken withdrawal by random call sequences, and shrink the sequence to the calls violating the invariant.
+func TestWemixInvariantFindWithdraw(t *testing.T) {
+	t.Parallel()
+	broken := changedWemix(t, "allPartners[_subIndex] = _lastP;",
+		"allPartners[_subIndex].serial = _lastP.serial; allPartners[_subIndex].payer = _lastP.payer;")
+	contract, err := backend.NewContract(broken, "WemixToken")
+	assert.NoError(t, err)
+	defer contract.Backend.Close()
+
+	ecoFund := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	wemix := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	actors := []*ecdsa.PrivateKey{newKey(t), newKey(t)}
+	holders := []common.Address{contract.Owner, ecoFund, wemix}
+	for _, key := range actors {
+		holders = append(holders, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	unitStaking := toBig(t, "2000000000000000000000000")
+	test := &backend.InvariantTest{
+		Contract:   contract,
+		DeployArgs: []interface{}{ecoFund, wemix},
+		Setup: func(c *backend.Contract) error {
+			//withdrawals without waiting, by the partners allowed to stake
+			if err := executeOk(c, nil, "change_minBlockWaitingWithdrawal", new(big.Int)); err != nil {
+				return err
+			}
+			for _, h := range holders[3:] {
+				if err := executeOk(c, nil, "transfer", h, unitStaking); err != nil {
+					return err
+				}
+				if err := executeOk(c, nil, "addAllowedPartner", h); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Actors:  actors,
+		Methods: []string{"stake", "withdraw", "transfer"},
+		Args: map[string]backend.ArgsFunc{
+			"stake": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{new(big.Int)}
+			},
+			"withdraw": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{big.NewInt(1 + r.Int63n(2))}
+			},
+			"transfer": func(r *rand.Rand, actors []common.Address) []interface{} {
+				return []interface{}{actors[r.Intn(len(actors))], big.NewInt(r.Int63n(10))}
+			},
+		},
+		Invariants: wemixInvariants(holders),
+		Runs:       50,
+		Depth:      20,
+		MaxBlocks:  2,
+		Seed:       1,
+	}
+
+	failure, err := test.Run()
+	assert.NoError(t, err)
+	if assert.NotNil(t, failure) == false {
+		return
+	}
+	t.Log(failure)
+	assert.Equal(t, "partners match the stakes not withdrawn", failure.Invariant)
+
+	//both partners stake, and the first one withdraws
+	if assert.Len(t, failure.Steps, 3) {
+		assert.Equal(t, "stake", failure.Steps[0].Method)
+		assert.Equal(t, "stake", failure.Steps[1].Method)
+		assert.Equal(t, "withdraw", failure.Steps[2].Method)
+		assert.Equal(t, failure.Steps[0].Actor, failure.Steps[2].Actor)
+	}
+	for _, s := range failure.Steps {
+		assert.Equal(t, uint64(0), s.Blocks, s.String())
+	}
+
+	//the shrunk sequence still violates the invariant on a new chain
+	replayed := contract.Clone()
+	defer replayed.Backend.Close()
+	assert.NoError(t, replayed.Deploy(test.DeployArgs...))
+	assert.NoError(t, test.Setup(replayed))
+	for _, s := range failure.Steps {
+		expecedSuccess(t, replayed, actors[s.Actor], s.Method, s.Args...)
+	}
+	for _, invariant := range test.Invariants {
+		if invariant.Name == failure.Invariant {
+			assert.Error(t, invariant.Check(replayed))
+		}
+	}
+}
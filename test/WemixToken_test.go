@@ -1,8 +1,12 @@
 package test
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,91 +41,70 @@ func (p *typePartner) log(serial *big.Int, t *testing.T) {
 
 //Retrieve and store all block partner information from the blockchain,
 func (p *typePartnerSlice) loadAllStake(t *testing.T, contract *backend.Contract) {
-	partnersNumber := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&partnersNumber, "partnersNumber"))
+	assert.NoError(t, p.load(contract))
+	t.Logf("ok > loadAllStake, partners number: %d", len(*p))
+}
+
+//load appends all block partner information in the blockchain.
+func (p *typePartnerSlice) load(contract *backend.Contract) error {
+	partnersNumber, err := backend.CallAs[*big.Int](contract, "partnersNumber")
+	if err != nil {
+		return err
+	}
 
 	for i := int64(0); i < partnersNumber.Int64(); i++ {
-		s := typePartner{}
-		assert.NoError(t, contract.Call(&s, "partnerByIndex", new(big.Int).SetInt64(i)))
+		s, err := backend.CallAs[typePartner](contract, "partnerByIndex", new(big.Int).SetInt64(i))
+		if err != nil {
+			return err
+		}
 		*p = append(*p, &s)
 	}
-	t.Logf("ok > loadAllStake, partners number: %d", partnersNumber)
+	return nil
 }
 
+var (
+	wemixPoolOnce sync.Once
+	wemixPool     *backend.Pool
+)
+
 //After compiling and distributing the contract, return the Contract pointer object.
+//WemixToken is compiled and deployed once in a pool, and each test gets its own copy of the chain,
+//so the tests calling it can run in parallel.
 func depolyWemix(t *testing.T) *backend.Contract {
-	contract, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
-	assert.NoError(t, err)
-
-	ecoFundKey, _ := crypto.GenerateKey()
-	wemixKey, _ := crypto.GenerateKey()
-
-	//deploy contract
-	args := []interface{}{
-		crypto.PubkeyToAddress(ecoFundKey.PublicKey), //ecoFund address
-		crypto.PubkeyToAddress(wemixKey.PublicKey),   //wemix address
-	}
-	if err := contract.Deploy(args...); err != nil {
+	wemixPoolOnce.Do(func() {
+		keys := backend.NewKeyRing("WemixToken")
+		args := []interface{}{
+			crypto.PubkeyToAddress(keys.Key("ecoFund").PublicKey), //ecoFund address
+			crypto.PubkeyToAddress(keys.Key("wemix").PublicKey),   //wemix address
+		}
+		pool, err := backend.NewPool("../contracts/WemixToken.sol", "WemixToken", nil, args...)
 		assert.NoError(t, err)
+		wemixPool = pool
+	})
+	if wemixPool == nil {
+		t.Fatal("WemixToken is not compiled")
 	}
-	return contract
+	return getFixture(t, wemixPool)
 }
 
-type Contract struct {
-	Code  string       `json:"code"`
-	RCode string       `json:"runtime-code"`
-	Info  ContractInfo `json:"info"`
-}
-
-type ContractInfo struct {
-	Source          string      `json:"source"`
-	Language        string      `json:"language"`
-	LanguageVersion string      `json:"languageVersion"`
-	CompilerVersion string      `json:"compilerVersion"`
-	CompilerOptions string      `json:"compilerOptions"`
-	AbiDefinition   interface{} `json:"abiDefinition"`
-	UserDoc         interface{} `json:"userDoc"`
-	DeveloperDoc    interface{} `json:"developerDoc"`
-	Metadata        string      `json:"metadata"`
+//changedWemix writes a copy of WemixToken.sol with the code replaced, and returns its path.
+//It is used to check that the tests find a deliberate bug.
+func changedWemix(t *testing.T, old, new string) string {
+	source, err := ioutil.ReadFile("../contracts/WemixToken.sol")
+	assert.NoError(t, err)
+	if bytes.Contains(source, []byte(old)) == false {
+		t.Fatalf("%q is not in WemixToken.sol", old)
+	}
+	path := filepath.Join(t.TempDir(), "WemixToken.sol")
+	assert.NoError(t, ioutil.WriteFile(path, bytes.Replace(source, []byte(old), []byte(new), 1), 0644))
+	return path
 }
 
 //Test to compile and deploy the contract
 func TestWemixDeploy(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
-	// jsonContract := &Contract{
-	// 	Code:  hexutil.Encode(contract.Code),
-	// 	RCode: hexutil.Encode(contract.Code),
-	// 	Info: ContractInfo{
-	// 		Source:          contract.Info.Source,
-	// 		Language:        contract.Info.Language,
-	// 		LanguageVersion: contract.Info.LanguageVersion,
-	// 		CompilerVersion: contract.Info.CompilerVersion,
-	// 		CompilerOptions: contract.Info.CompilerOptions,
-	// 		AbiDefinition:   contract.Info.AbiDefinition,
-	// 		UserDoc:         contract.Info.UserDoc,
-	// 		DeveloperDoc:    contract.Info.DeveloperDoc,
-	// 		Metadata:        contract.Info.Metadata,
-	// 	},
-	// }
-
-	// b, err := json.Marshal(jsonContract)
-	// assert.NoError(t, err)
-
-	// var buff bytes.Buffer
-	// gz := gzip.NewWriter(&buff)
-	// _, err = gz.Write(b)
-	// assert.NoError(t, err)
-
-	// assert.NoError(t, gz.Close())
-	// t.Log(hexutil.Encode(b))
-
-	// wemix := (*backend.Contract)(nil)
-	// if err := json.Unmarshal(b, &wemix); err != nil {
-	// 	assert.NoError(t, err)
-	// }
-	// contract = wemix
-
 	t.Log("contract source file:", contract.File)
 	t.Log("contract name:", contract.Name)
 	t.Log("contract Language:", contract.Info.Language)
@@ -131,15 +114,35 @@ func TestWemixDeploy(t *testing.T) {
 
 	t.Log("ok > contract address deployed", contract.Address.Hex())
 
-	// t.Log("contract bytecode:", hexutil.Encode(contract.Code))
-	// abiBytes, _ := json.Marshal(contract.Info.AbiDefinition)
-	// t.Log("contract abi:", string(abiBytes))
+	//save the artifact and load it back, attached to the chain
+	path := filepath.Join(t.TempDir(), "WemixToken.json")
+	assert.NoError(t, contract.Save(path))
 
+	loaded, err := backend.LoadContract(path, contract.Backend)
+	assert.NoError(t, err)
+	assert.Equal(t, contract.Address, loaded.Address)
+	assert.Equal(t, contract.Code, loaded.Code)
+	assert.Equal(t, contract.RuntimeCode, loaded.RuntimeCode)
+	assert.Equal(t, contract.BlockDeployed, loaded.BlockDeployed)
+	assert.Equal(t, contract.Deployer, loaded.Deployer)
+	assert.Equal(t, contract.DeployNonce, loaded.DeployNonce)
+	assert.True(t, loaded.DeployGas > 0)
+	assert.Equal(t, contract.DeployGas, loaded.DeployGas)
+	assert.Equal(t, toBytes(t, contract.ConstructorInputs), toBytes(t, loaded.ConstructorInputs))
+	assert.Equal(t, call[*big.Int](t, contract, "totalSupply"), call[*big.Int](t, loaded, "totalSupply"))
+
+	//and without a chain, to deploy it again
+	fresh, err := backend.LoadContract(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, fresh.Deploy(contract.ConstructorInputs...))
+	checkVariable(t, fresh, "ecoFund", contract.ConstructorInputs[0].(common.Address))
+	t.Log("ok > artifact saved and loaded", path)
 }
 
 //Test to verify the variables of the deployed contract.
 //Fatal if the expected value and the actual contract value differ.
 func TestWemixVariable(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 	block := contract.Backend.Blockchain().CurrentBlock().Header().Number
 
@@ -161,6 +164,7 @@ func TestWemixVariable(t *testing.T) {
 
 //Test to execute onlyOwner modifier method.
 func TestWemixExecute(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
 	executeChangeMethod(t, contract, "unitStaking", big.NewInt(1))
@@ -174,9 +178,10 @@ func TestWemixExecute(t *testing.T) {
 
 //test to run onlyOwner modifier method under non-owner account.
 func TestWemixOwner(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
-	key, _ := crypto.GenerateKey()
+	key := newKey(t)
 
 	expecedFail(t, contract, key, "change_unitStaking", big.NewInt(1))
 	expecedFail(t, contract, key, "change_minBlockWaitingWithdrawal", big.NewInt(1))
@@ -185,17 +190,18 @@ func TestWemixOwner(t *testing.T) {
 	expecedFail(t, contract, key, "change_mintToPartner", big.NewInt(1))
 	expecedFail(t, contract, key, "change_mintToWemix", big.NewInt(1))
 	expecedFail(t, contract, key, "transferOwnership", func() common.Address {
-		k, _ := crypto.GenerateKey()
+		k := newKey(t)
 		return crypto.PubkeyToAddress(k.PublicKey)
 	}())
 
-	newOwnerKey, _ := crypto.GenerateKey()
+	newOwnerKey := newKey(t)
 	expecedSuccess(t, contract, nil, "transferOwnership", crypto.PubkeyToAddress(newOwnerKey.PublicKey))
 	expecedSuccess(t, contract, newOwnerKey, "transferOwnership", contract.Owner)
 }
 
 //Test to run addAllowedStaker method.
 func TestWemixAllowedPartner(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
 	//make an error occur
@@ -204,7 +210,7 @@ func TestWemixAllowedPartner(t *testing.T) {
 	assert.True(t, r.Status == 0)
 
 	//make partner
-	partnerKey, _ := crypto.GenerateKey()
+	partnerKey := newKey(t)
 	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
 
 	//addAllowedPartner
@@ -229,20 +235,16 @@ func TestWemixAllowedPartner(t *testing.T) {
 
 //test staking
 func TestWemixStake(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
 	testStake(t, contract, false)
 }
 
 func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typeKeyMap {
-	unitStaking := func() *big.Int {
-		ret := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&ret, "unitStaking"))
-		return ret
-	}()
+	unitStaking := call[*big.Int](t, contract, "unitStaking")
 
-	minBlockWaitingWithdrawal := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&minBlockWaitingWithdrawal, "minBlockWaitingWithdrawal"))
+	minBlockWaitingWithdrawal := call[*big.Int](t, contract, "minBlockWaitingWithdrawal")
 
 	countExecuteStake := int64(0)
 	partnerKeyMap := typeKeyMap{}
@@ -272,8 +274,7 @@ func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typ
 		assert.NotNil(t, serial)
 		countExecuteStake++
 
-		result := typePartner{}
-		assert.NoError(t, contract.Call(&result, "partnerBySerial", serial))
+		result := call[typePartner](t, contract, "partnerBySerial", serial)
 		if showStakeInfo == true {
 			result.log(serial, t)
 		}
@@ -281,7 +282,7 @@ func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typ
 	}
 
 	makePartner := func() (common.Address, *ecdsa.PrivateKey) {
-		partnerKey, _ := crypto.GenerateKey()
+		partnerKey := newKey(t)
 		partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
 		partnerKeyMap[partner] = partnerKey
 		return partner, partnerKey
@@ -305,8 +306,7 @@ func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typ
 			assert.Equal(t, partner, result.Payer)
 
 			//when all tokens received have been exhausted, terminate staking.
-			balance := (*big.Int)(nil)
-			assert.NoError(t, contract.Call(&balance, "balanceOf", partner))
+			balance := call[*big.Int](t, contract, "balanceOf", partner)
 			if balance.Sign() == 0 {
 				break
 			}
@@ -335,8 +335,7 @@ func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typ
 	}
 
 	//Compare the number of block partners registered with the number registered on the blockchain.
-	partnersNumber := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&partnersNumber, "partnersNumber"))
+	partnersNumber := call[*big.Int](t, contract, "partnersNumber")
 	assert.True(t, partnersNumber.Cmp(new(big.Int).SetInt64(countExecuteStake)) == 0)
 
 	return partnerKeyMap
@@ -344,6 +343,7 @@ func testStake(t *testing.T, contract *backend.Contract, showStakeInfo bool) typ
 
 //test to withdraw
 func TestWemixWithdraw(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 
 	//change withdrawalWaitingMinBlockd short for testing.
@@ -356,8 +356,7 @@ func TestWemixWithdraw(t *testing.T) {
 	stakes := typePartnerSlice{}
 	stakes.loadAllStake(t, contract)
 
-	contractBalance := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&contractBalance, "balanceOf", contract.Address))
+	contractBalance := call[*big.Int](t, contract, "balanceOf", contract.Address)
 
 	totalStakeBalance := new(big.Int)
 	for i := 0; i < len(stakes); i++ {
@@ -399,8 +398,7 @@ func TestWemixWithdraw(t *testing.T) {
 	}
 
 	for staker, key := range partnerKeyMap {
-		balance := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&balance, "balanceOf", staker))
+		balance := call[*big.Int](t, contract, "balanceOf", staker)
 		if balance.Sign() > 0 {
 			r, err := contract.Execute(key, "transfer", contract.Owner, balance)
 			assert.NoError(t, err)
@@ -420,45 +418,29 @@ func testMint(t *testing.T, contract *backend.Contract) {
 			if _, ok := m[s.Partner]; ok == true {
 				continue
 			}
-			b := (*big.Int)(nil)
-			assert.NoError(t, contract.Call(&b, "balanceOf", s.Partner))
+			b := call[*big.Int](t, contract, "balanceOf", s.Partner)
 			m[s.Partner] = b
 		}
 		return m
 	}()
 
-	wemix := common.Address{}
-	assert.NoError(t, contract.Call(&wemix, "wemix"))
+	wemix := call[common.Address](t, contract, "wemix")
 
-	balanceWemix := func() *big.Int {
-		b := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&b, "balanceOf", wemix))
-		return b
-	}()
+	balanceWemix := call[*big.Int](t, contract, "balanceOf", wemix)
 
-	ecoFund := common.Address{}
-	assert.NoError(t, contract.Call(&ecoFund, "ecoFund"))
+	ecoFund := call[common.Address](t, contract, "ecoFund")
 
-	balanceEcoFund := func() *big.Int {
-		b := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&b, "balanceOf", ecoFund))
-		return b
-	}()
+	balanceEcoFund := call[*big.Int](t, contract, "balanceOf", ecoFund)
 
-	mintToPartner := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&mintToPartner, "mintToPartner"))
+	mintToPartner := call[*big.Int](t, contract, "mintToPartner")
 
-	mintToWemix := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&mintToWemix, "mintToWemix"))
+	mintToWemix := call[*big.Int](t, contract, "mintToWemix")
 
-	mintToEcoFund := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&mintToEcoFund, "mintToEcoFund"))
+	mintToEcoFund := call[*big.Int](t, contract, "mintToEcoFund")
 
-	nextPartnerToMint := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&nextPartnerToMint, "nextPartnerToMint"))
+	nextPartnerToMint := call[*big.Int](t, contract, "nextPartnerToMint")
 
-	blockUnitForMint := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&blockUnitForMint, "blockUnitForMint"))
+	blockUnitForMint := call[*big.Int](t, contract, "blockUnitForMint")
 
 	indexNext := int(nextPartnerToMint.Uint64())
 	totalMinted := new(big.Int)
@@ -482,17 +464,14 @@ func testMint(t *testing.T, contract *backend.Contract) {
 		totalMinted.Add(totalMinted, new(big.Int).Mul(mintToEcoFund, blockUnitForMint))
 	}
 
-	initialTotalSupply := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&initialTotalSupply, "totalSupply"))
+	initialTotalSupply := call[*big.Int](t, contract, "totalSupply")
 
-	startBlock := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&startBlock, "blockToMint"))
+	startBlock := call[*big.Int](t, contract, "blockToMint")
 
 	timesMinting := uint64(210)
 
 	for i := uint64(0); i < timesMinting; i++ {
-		blockToMint := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&blockToMint, "blockToMint"))
+		blockToMint := call[*big.Int](t, contract, "blockToMint")
 
 		currentBlock := contract.Backend.Blockchain().CurrentBlock().Header().Number
 		if currentBlock.Cmp(blockToMint) < 0 {
@@ -502,22 +481,19 @@ func testMint(t *testing.T, contract *backend.Contract) {
 				contract.Backend.Commit() //make block
 			}
 		}
-		key, _ := crypto.GenerateKey()
+		key := newKey(t)
 		r, err := contract.Execute(key, "mint")
 		assert.NoError(t, err)
 		assert.True(t, r.Status == 1)
 		countExpectedBalance()
 	}
-	endBlock := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&endBlock, "blockToMint"))
+	endBlock := call[*big.Int](t, contract, "blockToMint")
 
-	isMintable := bool(false)
-	assert.NoError(t, contract.Call(&isMintable, "isMintable"))
+	isMintable := call[bool](t, contract, "isMintable")
 	assert.True(t, isMintable == false)
 
 	checkBalance := func(tag string, addr common.Address, expected *big.Int) {
-		got := (*big.Int)(nil)
-		assert.NoError(t, contract.Call(&got, "balanceOf", addr))
+		got := call[*big.Int](t, contract, "balanceOf", addr)
 		assert.True(t, expected.Cmp(got) == 0)
 		t.Logf("ok > %s(%s) balance  expected:%v, got:%v", tag, addr.Hex(), expected, got)
 	}
@@ -528,8 +504,7 @@ func testMint(t *testing.T, contract *backend.Contract) {
 	checkBalance("wemix", wemix, balanceWemix)
 	checkBalance("ecoFund", ecoFund, balanceEcoFund)
 
-	totalSupply := (*big.Int)(nil)
-	assert.NoError(t, contract.Call(&totalSupply, "totalSupply"))
+	totalSupply := call[*big.Int](t, contract, "totalSupply")
 	expected := new(big.Int).Add(initialTotalSupply, totalMinted)
 	assert.True(t, totalSupply.Cmp(expected) == 0)
 	t.Logf("ok > match totalSupply and expected totalSupply after mint, got :%d, expectd: %d", totalSupply, expected)
@@ -537,6 +512,7 @@ func testMint(t *testing.T, contract *backend.Contract) {
 
 //After registering block partners, do minting test and check the amount of minting.
 func TestWemixMint(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 	testStake(t, contract, true)
 
@@ -545,6 +521,7 @@ func TestWemixMint(t *testing.T) {
 
 //Test minting without block partner and check minting amount.
 func TestWemixMintWithoutPartner(t *testing.T) {
+	t.Parallel()
 	contract := depolyWemix(t)
 	testMint(t, contract)
 }
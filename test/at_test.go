@@ -0,0 +1,53 @@
+package test
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to bind counters to the address of a counter created by the factory.
+func TestContractAt(t *testing.T) {
+	t.Parallel()
+	counter, err := backend.NewContract("contracts/Counter.sol", "CounterV1")
+	assert.NoError(t, err)
+	defer counter.Backend.Close()
+	assert.NoError(t, counter.DeployCreate2(common.HexToHash("0x01")))
+	owner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	expecedSuccess(t, counter, nil, "initialize", owner, big.NewInt(7))
+
+	bound, err := backend.At("contracts/Counter.sol", "CounterV1", counter.Backend, counter.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, counter.Address, bound.Address)
+	assert.Equal(t, int64(7), call[*big.Int](t, bound, "count").Int64())
+	assert.Equal(t, owner, call[common.Address](t, bound, "owner"))
+	assert.Nil(t, bound.BlockDeployed)
+
+	//the address of a bound contract is saved without a deployment
+	boundPath := filepath.Join(t.TempDir(), "bound.json")
+	assert.NoError(t, bound.Save(boundPath))
+	reloaded, err := backend.LoadContract(boundPath, counter.Backend)
+	assert.NoError(t, err)
+	assert.Equal(t, counter.Address, reloaded.Address)
+	assert.Nil(t, reloaded.BlockDeployed)
+	assert.Equal(t, int64(7), call[*big.Int](t, reloaded, "count").Int64())
+
+	path := filepath.Join(t.TempDir(), "counter.json")
+	assert.NoError(t, counter.Save(path))
+	loaded, err := backend.AtArtifact(path, counter.Backend, counter.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), call[*big.Int](t, loaded, "count").Int64())
+
+	//no code at the address
+	_, err = counter.At(owner)
+	assert.Error(t, err)
+	_, err = backend.AtArtifact(path, nil, counter.Address)
+	assert.Error(t, err)
+	t.Log("ok > bound to", bound.Address.Hex())
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Test to generate typed bindings of WemixToken.
+func TestWemixBind(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	code, err := contract.Bind("wemix")
+	assert.NoError(t, err)
+
+	//type-check the bindings with the packages they import,
+	//and that the fields of the contract are not hidden by the methods of the same names
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "wemix.go", code, 0)
+	assert.NoError(t, err)
+	use, err := parser.ParseFile(fset, "use.go", `package wemix
+
+var _ string = (&WemixToken{}).Name
+var _ func() string = (&WemixToken{}).Owner.Hex
+`, 0)
+	assert.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("wemix", fset, []*ast.File{file, use}, nil)
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"func (p *WemixToken) UnitStaking() (*big.Int, error)",
+		"func (p *WemixToken) StakeDelegated(key *ecdsa.PrivateKey, partner common.Address, withdrawalWaitingMinBlock *big.Int) (*types.Receipt, error)",
+		"func (p *WemixToken) PartnerBySerial(serial *big.Int) (*WemixTokenPartnerBySerialOutput, error)",
+		"func (p *WemixToken) ParseStaked(log *types.Log) (*WemixTokenStaked, error)",
+		"func (p *WemixToken) NameMethod() (string, error)",
+		"func (p *WemixToken) OwnerMethod() (common.Address, error)",
+	} {
+		assert.True(t, strings.Contains(code, expected), expected)
+	}
+	t.Log("ok > bindings generated, size:", len(code))
+}
+
+//Test to unpack the Staked event from the topics.
+func TestWemixUnpackLog(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+	expecedSuccess(t, contract, nil, "addAllowedPartner", partner)
+
+	r, err := contract.Execute(nil, "stakeDelegated", partner, new(big.Int))
+	assert.NoError(t, err)
+	assert.True(t, r.Status == 1)
+
+	logs := contract.LogsOf("Staked", r)
+	assert.Equal(t, 1, len(logs))
+
+	staked := struct {
+		Partner common.Address
+		Payer   common.Address
+		Serial  *big.Int
+	}{}
+	assert.NoError(t, contract.UnpackLog(&staked, "Staked", logs[0]))
+	assert.Equal(t, partner, staked.Partner)
+	assert.Equal(t, contract.Owner, staked.Payer)
+	assert.True(t, staked.Serial.Cmp(big.NewInt(1)) == 0)
+}
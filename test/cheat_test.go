@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to set balances, nonces, code and storage with cheatcodes, and replay them on a new chain.
+func TestWemixCheat(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	ctx := context.Background()
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+
+	assert.NoError(t, backend.SetBalance(contract.Backend, partner, big.NewInt(1e18)))
+	balance, err := contract.Backend.BalanceAt(ctx, partner, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e18), balance)
+
+	assert.NoError(t, backend.SetNonce(contract.Backend, partner, 5))
+	nonce, err := contract.Backend.NonceAt(ctx, partner, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), nonce)
+	//the next tx of the partner takes the nonce
+	expecedSuccess(t, contract, partnerKey, "transfer", partner, big.NewInt(0))
+
+	//10M WEMIX held by the partner
+	amount := new(big.Int).Mul(big.NewInt(10000000), big.NewInt(1e18))
+	assert.NoError(t, contract.SetVariable("_balances", common.BigToHash(amount), common.BytesToHash(partner.Bytes())))
+	assert.Equal(t, amount, call[*big.Int](t, contract, "balanceOf", partner))
+	assert.Error(t, contract.SetVariable("_balances", common.BigToHash(amount)))
+	assert.Error(t, contract.SetVariable("noSuchVariable", common.Hash{}))
+
+	//a copy of the token at another address
+	copied := crypto.CreateAddress(partner, 100)
+	code, err := contract.Backend.CodeAt(ctx, contract.Address, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, backend.SetCode(contract.Backend, copied, code))
+	layout, err := contract.StorageLayout()
+	assert.NoError(t, err)
+	balances, err := layout.Lookup("_balances")
+	assert.NoError(t, err)
+	slot := crypto.Keccak256Hash(common.BytesToHash(partner.Bytes()).Bytes(), common.BigToHash(new(big.Int).SetUint64(balances.Slot)).Bytes())
+	assert.NoError(t, backend.SetStorage(contract.Backend, copied, slot, common.BigToHash(big.NewInt(7))))
+	other, err := contract.At(copied)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), call[*big.Int](t, other, "balanceOf", partner).Int64())
+
+	//cheats do not drop pending transactions
+	nonce, err = contract.Backend.PendingNonceAt(ctx, partner)
+	assert.NoError(t, err)
+	tx, err := types.SignTx(types.NewTransaction(nonce, partner, big.NewInt(1), 21000, big.NewInt(0), nil), types.HomesteadSigner{}, partnerKey)
+	assert.NoError(t, err)
+	assert.NoError(t, contract.Backend.SendTransaction(ctx, tx))
+	assert.Error(t, backend.SetBalance(contract.Backend, partner, big.NewInt(1e18)))
+	contract.Backend.Commit()
+
+	//the cheats are replayed from the journal
+	journal, err := backend.RecordJournal(contract.Backend)
+	assert.NoError(t, err)
+	b := backend.NewBackend()
+	defer b.Close()
+	assert.NoError(t, journal.Replay(b))
+	replayed := *contract
+	replayed.Backend = b
+	assert.Equal(t, amount, call[*big.Int](t, &replayed, "balanceOf", partner))
+	assert.Equal(t, contract.Backend.Blockchain().CurrentBlock().Hash(), b.Blockchain().CurrentBlock().Hash())
+	t.Log("ok > cheats at block", b.Blockchain().CurrentBlock().Number())
+}
+
+//Test to set a variable packed in a slot with other variables.
+func TestCounterCheatPacked(t *testing.T) {
+	t.Parallel()
+	counter, err := backend.NewContract("contracts/Counter.sol", "CounterV1")
+	assert.NoError(t, err)
+	defer counter.Backend.Close()
+	assert.NoError(t, counter.Deploy())
+	owner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	expecedSuccess(t, counter, nil, "initialize", owner, big.NewInt(1))
+
+	//owner shares the slot with initialized, which is kept
+	other := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	assert.NoError(t, counter.SetVariable("owner", common.BytesToHash(other.Bytes())))
+	assert.Equal(t, other, call[common.Address](t, counter, "owner"))
+	expecedFail(t, counter, nil, "initialize", owner, big.NewInt(0))
+
+	assert.NoError(t, counter.SetVariable("CounterV1.count", common.BigToHash(big.NewInt(42))))
+	assert.Equal(t, int64(42), call[*big.Int](t, counter, "count").Int64())
+}
+
+//Test to refuse a variable taking several slots, which is set by its slots.
+func TestCounterCheatWide(t *testing.T) {
+	t.Parallel()
+	counter, err := backend.NewContract("contracts/Counter.sol", "CounterHistory")
+	assert.NoError(t, err)
+	defer counter.Backend.Close()
+	assert.NoError(t, counter.Deploy())
+
+	assert.Error(t, counter.SetVariable("last", common.BigToHash(big.NewInt(7))))
+	assert.NoError(t, backend.SetStorage(counter.Backend, counter.Address, common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(7))))
+	assert.Equal(t, int64(7), call[*big.Int](t, counter, "last", big.NewInt(1)).Int64())
+
+	//the variable after the array
+	assert.NoError(t, counter.SetVariable("count", common.BigToHash(big.NewInt(5))))
+	assert.Equal(t, int64(5), call[*big.Int](t, counter, "count").Int64())
+}
@@ -0,0 +1,14 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/wemade-tree/contract-test/conformance"
+)
+
+//Test WemixToken with the ERC-20 conformance suite, the owner holding the initial supply.
+func TestWemixERC20(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	conformance.RunERC20(t, contract, contract.OwnerKey)
+}
@@ -0,0 +1,61 @@
+pragma solidity >= 0.6.0 <0.7.0;
+
+//CounterV1 is an upgradeable counter to test proxies, initialized instead of constructed.
+contract CounterV1 {
+    bool private initialized;
+    address public owner;
+    uint256 public count;
+
+    function initialize(address _owner, uint256 _count) public {
+        require(initialized == false, "Counter: initialized already");
+        initialized = true;
+        owner = _owner;
+        count = _count;
+    }
+
+    function increment() public {
+        count += 1;
+    }
+
+    function version() public pure virtual returns (string memory) {
+        return "v1";
+    }
+}
+
+//CounterV2 appends a variable and methods to CounterV1, keeping its storage layout.
+contract CounterV2 is CounterV1 {
+    uint256 public step;
+
+    function setStep(uint256 _step) public {
+        require(msg.sender == owner, "Counter: caller is not the owner");
+        step = _step;
+    }
+
+    function incrementByStep() public {
+        count += step;
+    }
+
+    function version() public pure override returns (string memory) {
+        return "v2";
+    }
+}
+
+//CounterMoved puts a variable before the ones of CounterV1 and changes the type of count,
+//which is not a safe upgrade of CounterV1.
+contract CounterMoved {
+    uint256 public step;
+    bool private initialized;
+    address public owner;
+    uint128 public count;
+}
+
+//CounterHistory keeps the last counts in a static array, which takes several slots.
+contract CounterHistory {
+    uint256[3] public last;
+    uint256 public count;
+
+    function increment() public {
+        last[count % 3] = count;
+        count += 1;
+    }
+}
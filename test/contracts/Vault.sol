@@ -0,0 +1,25 @@
+pragma solidity >= 0.6.0 <0.7.0;
+
+interface IERC20 {
+    function balanceOf(address account) external view returns (uint256);
+    function transferFrom(address sender, address recipient, uint256 amount) external returns (bool);
+}
+
+//Vault keeps deposits of a token, to test contracts calling a mocked token.
+contract Vault {
+    IERC20 public token;
+    mapping(address => uint256) public deposits;
+
+    constructor(IERC20 _token) public {
+        token = _token;
+    }
+
+    function deposit(uint256 amount) public {
+        require(token.transferFrom(msg.sender, address(this), amount), "Vault: transfer failed");
+        deposits[msg.sender] += amount;
+    }
+
+    function holdings() public view returns (uint256) {
+        return token.balanceOf(address(this));
+    }
+}
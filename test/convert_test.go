@@ -0,0 +1,37 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Test to execute and call methods with arguments converted against the ABI.
+func TestWemixLenientArgs(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	contract.Lenient = true
+
+	key := newKey(t)
+	partner := crypto.PubkeyToAddress(key.PublicKey)
+
+	expecedSuccess(t, contract, nil, "transfer", partner.Hex(), "0.5 ether")
+	expecedSuccess(t, contract, nil, "transfer", partner.Hex(), "0x0de0b6b3a7640000") //1 ether
+	expecedSuccess(t, contract, nil, "transfer", partner.Hex(), 2)
+	expecedSuccess(t, contract, nil, "change_unitStaking", "1000")
+
+	balance := call[*big.Int](t, contract, "balanceOf", partner.Hex())
+	assert.Equal(t, "1500000000000000002", balance.String())
+	checkVariable(t, contract, "unitStaking", big.NewInt(1000))
+
+	for _, arg := range []interface{}{"-1", "0.1 wei", "1 lightyear", "0x10000000000000000000000000000000000000000000000000000000000000000", 1.5} {
+		_, err := contract.Execute(nil, "transfer", partner, arg)
+		assert.Error(t, err)
+		t.Log("ok > rejected:", err)
+	}
+	_, err := contract.Execute(nil, "transfer", "0x1234", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to deploy counters with CREATE2 at the precomputed addresses, which are the same on another chain.
+func TestCreate2Deploy(t *testing.T) {
+	t.Parallel()
+	salt := common.HexToHash("0x01")
+
+	counter, err := backend.NewContract("contracts/Counter.sol", "CounterV1")
+	assert.NoError(t, err)
+	defer counter.Backend.Close()
+
+	address, err := counter.Create2Address(salt)
+	assert.NoError(t, err)
+	assert.NoError(t, counter.DeployCreate2(salt))
+	assert.Equal(t, address, counter.Address)
+	assert.Equal(t, backend.Create2FactoryAddress, counter.Deployer)
+
+	code, err := counter.Backend.CodeAt(context.Background(), counter.Address, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, counter.RuntimeCode, code)
+
+	owner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	expecedSuccess(t, counter, nil, "initialize", owner, big.NewInt(3))
+	assert.Equal(t, int64(3), call[*big.Int](t, counter, "count").Int64())
+
+	//the same salt and init code can not be deployed twice on a chain
+	second := *counter
+	assert.Error(t, second.DeployCreate2(salt))
+	assert.NoError(t, second.DeployCreate2(common.HexToHash("0x02")))
+	assert.NotEqual(t, counter.Address, second.Address)
+
+	//the factory and the contract have the same addresses on another chain
+	other := counter.Clone()
+	defer other.Backend.Close()
+	assert.NoError(t, other.DeployCreate2(salt))
+	assert.Equal(t, counter.Address, other.Address)
+	t.Log("ok > counter at", counter.Address.Hex(), "by factory at", backend.Create2FactoryAddress.Hex())
+}
+
+//Test to deploy counters with CREATE2 from parallel subtests on a fresh chain, which has no factory yet.
+func TestCreate2Parallel(t *testing.T) {
+	t.Parallel()
+	counter, err := backend.NewContract("contracts/Counter.sol", "CounterV1")
+	assert.NoError(t, err)
+	defer counter.Backend.Close()
+
+	t.Run("parallel", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			salt := common.BigToHash(big.NewInt(int64(i)))
+			t.Run("deploy", func(t *testing.T) {
+				t.Parallel()
+				c := *counter
+				assert.NoError(t, c.DeployCreate2(salt))
+				address, err := c.Create2Address(salt)
+				assert.NoError(t, err)
+				assert.Equal(t, address, c.Address)
+			})
+		}
+	})
+}
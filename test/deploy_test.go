@@ -0,0 +1,38 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Test to deploy WemixToken again on the same chain after the owner has sent transactions.
+func TestWemixRedeploy(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	assert.Equal(t, contract.Owner, contract.Deployer)
+	assert.Equal(t, uint64(0), contract.DeployNonce)
+	assert.Equal(t, crypto.CreateAddress(contract.Deployer, contract.DeployNonce), contract.Address)
+
+	partner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	expecedSuccess(t, contract, nil, "addAllowedPartner", partner)
+
+	second, err := contract.Redeploy(contract.ConstructorInputs...)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), second.DeployNonce)
+	assert.Equal(t, crypto.CreateAddress(second.Deployer, second.DeployNonce), second.Address)
+	assert.NotEqual(t, contract.Address, second.Address)
+	assert.Equal(t, contract.Backend, second.Backend)
+
+	//the instances have their own state
+	assert.True(t, call[bool](t, contract, "allowedPartners", partner))
+	assert.False(t, call[bool](t, second, "allowedPartners", partner))
+
+	expecedSuccess(t, second, nil, "transfer", partner, big.NewInt(1))
+	assert.Equal(t, int64(1), call[*big.Int](t, second, "balanceOf", partner).Int64())
+	assert.Equal(t, int64(0), call[*big.Int](t, contract, "balanceOf", partner).Int64())
+	t.Log("ok > second instance at", second.Address.Hex())
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"encoding/gob"
+	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,6 +17,43 @@ import (
 
 type typeKeyMap map[common.Address]*ecdsa.PrivateKey
 
+var (
+	keyRingsLock sync.Mutex
+	keyRings     = map[*testing.T]*backend.KeyRing{}
+)
+
+//newKey returns the next key derived from the seed and the test name,
+//and logs the seed to reproduce the run if the test fails.
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	keyRingsLock.Lock()
+	defer keyRingsLock.Unlock()
+
+	ring, ok := keyRings[t]
+	if ok == false {
+		ring = backend.NewKeyRing(t.Name())
+		keyRings[t] = ring
+		t.Cleanup(func() {
+			if t.Failed() {
+				t.Logf("reproduce with %s=%s", backend.SeedEnv, ring.Seed())
+			}
+			keyRingsLock.Lock()
+			delete(keyRings, t)
+			keyRingsLock.Unlock()
+		})
+	}
+	return ring.Next()
+}
+
+//getFixture returns a copy of the fixture contract of the pool, whose backend is closed when the test ends.
+func getFixture(t *testing.T, pool *backend.Pool) *backend.Contract {
+	contract, closeBackend, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(closeBackend)
+	return contract
+}
+
 //Converts the given data into a byte slice and returns it.
 func toBytes(t *testing.T, data interface{}) []byte {
 	var buf bytes.Buffer
@@ -29,19 +68,24 @@ func toBig(t *testing.T, value10 string) *big.Int {
 	return ret
 }
 
-//checkVariable compares value stored in the blockchain with a given expected value
-func checkVariable(t *testing.T, contract *backend.Contract, method string, expected interface{}) {
-	ret, err := contract.LowCall(method)
+//call invokes a view method and returns its output as T.
+func call[T any](t *testing.T, contract *backend.Contract, method string, args ...interface{}) T {
+	ret, err := backend.CallAs[T](contract, method, args...)
 	assert.NoError(t, err)
-	assert.Equal(t, toBytes(t, ret[0]), toBytes(t, expected))
+	return ret
+}
 
-	switch expected.(type) {
+//checkVariable compares value stored in the blockchain with a given expected value
+func checkVariable[T any](t *testing.T, contract *backend.Contract, method string, expected T) {
+	ret := call[T](t, contract, method)
+	assert.Equal(t, toBytes(t, ret), toBytes(t, expected))
+
+	switch v := interface{}(ret).(type) {
 	case common.Address:
-		t.Log(method, ret[0].(common.Address).Hex())
+		t.Log(method, v.Hex())
 	default:
-		t.Log(method, ret[0])
+		t.Log(method, ret)
 	}
-
 }
 
 //executeChangeMethod executes the method with the "change_" prefix in the contract,
@@ -72,3 +116,15 @@ func expecedSuccess(t *testing.T, contract *backend.Contract, key *ecdsa.Private
 	assert.NoError(t, err)
 	assert.True(t, r.Status == 1)
 }
+
+//executeOk executes the method and returns an error if the transaction fails.
+func executeOk(contract *backend.Contract, key *ecdsa.PrivateKey, method string, arg ...interface{}) error {
+	r, err := contract.Execute(key, method, arg...)
+	if err != nil {
+		return err
+	}
+	if r.Status != 1 {
+		return fmt.Errorf("%s: status of tx receipt: %v", method, r.Status)
+	}
+	return nil
+}
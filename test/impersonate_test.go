@@ -0,0 +1,54 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to send transactions as the ecoFund address and the contract itself without their keys.
+func TestWemixImpersonate(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	ecoFund := call[common.Address](t, contract, "ecoFund")
+	partner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	expecedSuccess(t, contract, nil, "transfer", ecoFund, big.NewInt(100))
+
+	receipt, err := contract.ExecuteAs(ecoFund, "transfer", partner, big.NewInt(40))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, int64(60), call[*big.Int](t, contract, "balanceOf", ecoFund).Int64())
+	assert.Equal(t, int64(40), call[*big.Int](t, contract, "balanceOf", partner).Int64())
+
+	tx := contract.Backend.Blockchain().GetBlockByHash(receipt.BlockHash).Transactions()[0]
+	from, ok := backend.Impersonated(tx)
+	assert.True(t, ok)
+	assert.Equal(t, ecoFund, from)
+
+	//the nonce of the impersonated account goes on
+	receipt, err = contract.ExecuteAs(ecoFund, "transfer", partner, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+
+	//the contract holds no tokens
+	receipt, err = contract.ExecuteAs(contract.Address, "transfer", partner, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), receipt.Status)
+
+	//impersonated transactions are replayed from the journal
+	journal, err := backend.RecordJournal(contract.Backend)
+	assert.NoError(t, err)
+	b := backend.NewBackend()
+	defer b.Close()
+	assert.NoError(t, journal.Replay(b))
+	replayed := *contract
+	replayed.Backend = b
+	assert.Equal(t, int64(50), call[*big.Int](t, &replayed, "balanceOf", ecoFund).Int64())
+	assert.Equal(t, int64(50), call[*big.Int](t, &replayed, "balanceOf", partner).Int64())
+	t.Log("ok > impersonated", ecoFund.Hex())
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test that the keys derived from the same seed are the same, so that a run can be reproduced.
+func TestDeriveKey(t *testing.T) {
+	a, b := backend.NewKeyRing(t.Name()), backend.NewKeyRing(t.Name())
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, crypto.FromECDSA(a.Next()), crypto.FromECDSA(b.Next()))
+	}
+	assert.Equal(t, crypto.FromECDSA(a.Key("partner")), crypto.FromECDSA(b.Key("partner")))
+	assert.NotEqual(t, crypto.FromECDSA(a.Key("partner")), crypto.FromECDSA(a.Key("ecoFund")))
+	assert.NotEqual(t, crypto.FromECDSA(backend.DeriveKey("1", "owner")), crypto.FromECDSA(backend.DeriveKey("2", "owner")))
+
+	first, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	second, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Owner, second.Owner)
+	assert.NoError(t, first.Deploy(crypto.PubkeyToAddress(a.Key("ecoFund").PublicKey), crypto.PubkeyToAddress(a.Key("wemix").PublicKey)))
+	assert.NoError(t, second.Deploy(crypto.PubkeyToAddress(b.Key("ecoFund").PublicKey), crypto.PubkeyToAddress(b.Key("wemix").PublicKey)))
+	assert.Equal(t, first.Address, second.Address)
+	t.Log("ok > keys derived from seed", a.Seed())
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to compare the storage layouts of the counter versions and of WemixToken with itself.
+func TestStorageLayout(t *testing.T) {
+	t.Parallel()
+	compile := func(file, name string) *backend.Contract {
+		c, err := backend.Compile(file, name)
+		assert.NoError(t, err)
+		return c
+	}
+	v1 := compile("contracts/Counter.sol", "CounterV1")
+	v2 := compile("contracts/Counter.sol", "CounterV2")
+	moved := compile("contracts/Counter.sol", "CounterMoved")
+	wemix := compile("../contracts/WemixToken.sol", "WemixToken")
+
+	layout, err := wemix.StorageLayout()
+	assert.NoError(t, err)
+	for _, label := range []string{"allPartners", "allPartnersIndex", "_nextSerial", "_balances"} {
+		v, err := layout.Lookup(label)
+		assert.NoError(t, err, label)
+		t.Log(v)
+	}
+	assert.NoError(t, backend.CheckUpgrade(wemix, wemix))
+
+	//appending is safe
+	assert.NoError(t, backend.CheckUpgrade(v1, v2))
+	v1Layout, err := v1.StorageLayout()
+	assert.NoError(t, err)
+	v2Layout, err := v2.StorageLayout()
+	assert.NoError(t, err)
+	changes := backend.CompareLayout(v1Layout, v2Layout)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "appended", changes[0].Kind)
+	assert.Equal(t, "step", changes[0].Label)
+
+	//moving and retyping are not
+	assert.Error(t, backend.CheckUpgrade(v1, moved))
+	movedLayout, err := moved.StorageLayout()
+	assert.NoError(t, err)
+	kinds := map[string][]string{}
+	for _, c := range backend.CompareLayout(v1Layout, movedLayout) {
+		kinds[c.Kind] = append(kinds[c.Kind], c.Label)
+		t.Log(c)
+	}
+	assert.Equal(t, []string{"initialized", "owner", "count"}, kinds["moved"])
+	assert.Equal(t, []string{"count"}, kinds["retyped"])
+	assert.Equal(t, []string{"step"}, kinds["inserted"])
+
+	//removing is not
+	assert.Error(t, backend.CheckUpgrade(v2, v1))
+}
+
+//Test to compare storage layouts whose base contracts declare variables of the same label, as older solc allows.
+func TestStorageLayoutSameLabel(t *testing.T) {
+	older := backend.StorageLayout{
+		{Label: "owner", Contract: "Ownable", Slot: 0, Type: "t_address", Bytes: 20},
+		{Label: "owner", Contract: "Token", Slot: 1, Type: "t_address", Bytes: 20},
+		{Label: "supply", Contract: "Token", Slot: 2, Type: "t_uint256", Bytes: 32},
+	}
+	//Token.owner is retyped, and Ownable.owner is kept
+	newer := backend.StorageLayout{
+		{Label: "owner", Contract: "Ownable", Slot: 0, Type: "t_address", Bytes: 20},
+		{Label: "owner", Contract: "Token", Slot: 1, Type: "t_uint256", Bytes: 32},
+		{Label: "supply", Contract: "TokenV2", Slot: 2, Type: "t_uint256", Bytes: 32},
+	}
+
+	changes := backend.CompareLayout(older, newer)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "retyped", changes[0].Kind)
+	assert.Equal(t, "Token", changes[0].Old.Contract)
+	assert.Equal(t, "Token", changes[0].New.Contract)
+
+	_, err := older.Lookup("owner")
+	assert.Error(t, err)
+	v, err := older.Lookup("Token.owner")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v.Slot)
+	v, err = newer.Lookup("supply")
+	assert.NoError(t, err)
+	assert.Equal(t, "TokenV2", v.Contract)
+}
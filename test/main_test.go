@@ -0,0 +1,19 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//TestMain runs the tests and prints the seed if any of them fails.
+//Fixtures like depolyWemix derive the owner keys from the seed without newKey, which logs it for a test.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code != 0 {
+		fmt.Fprintf(os.Stderr, "reproduce with %s=%s\n", backend.SeedEnv, backend.Seed())
+	}
+	os.Exit(code)
+}
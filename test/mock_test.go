@@ -0,0 +1,67 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test a vault depositing a mocked WemixToken.
+func TestMockToken(t *testing.T) {
+	t.Parallel()
+	wemix, err := backend.NewContract("../contracts/WemixToken.sol", "WemixToken")
+	assert.NoError(t, err)
+	defer wemix.Backend.Close()
+	token, err := backend.NewMock(wemix)
+	assert.NoError(t, err)
+
+	vault, err := backend.Compile("contracts/Vault.sol", "Vault")
+	assert.NoError(t, err)
+	vault.Backend = token.Backend
+	assert.NoError(t, vault.Deploy(token.Address))
+
+	//methods without a response revert
+	userKey := newKey(t)
+	user := crypto.PubkeyToAddress(userKey.PublicKey)
+	expecedFail(t, vault, userKey, "deposit", big.NewInt(10))
+
+	assert.NoError(t, token.Returns("balanceOf", big.NewInt(1000)))
+	assert.Equal(t, int64(1000), call[*big.Int](t, vault, "holdings").Int64())
+
+	assert.NoError(t, token.Returns("transferFrom", true))
+	expecedSuccess(t, vault, userKey, "deposit", big.NewInt(10))
+	assert.Equal(t, int64(10), call[*big.Int](t, vault, "deposits", user).Int64())
+
+	calls, err := token.Calls("transferFrom")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, vault.Address, calls[0].From)
+	assert.Equal(t, []interface{}{user, vault.Address, big.NewInt(10)}, calls[0].Args)
+
+	//a false return and a revert fail the deposit
+	assert.NoError(t, token.Returns("transferFrom", false))
+	expecedFail(t, vault, userKey, "deposit", big.NewInt(10))
+	reason, err := vault.RevertReason(user, "deposit", big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, "Vault: transfer failed", reason)
+
+	assert.NoError(t, token.Reverts("transferFrom", "ERC20: transfer amount exceeds balance"))
+	reason, err = vault.RevertReason(user, "deposit", big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", reason)
+
+	//the mock is called directly too
+	assert.Equal(t, int64(1000), call[*big.Int](t, token.Contract, "balanceOf", common.Address{}).Int64())
+	assert.Error(t, token.Returns("noSuchMethod"))
+
+	assert.NoError(t, token.Reset())
+	calls, err = token.Calls("")
+	assert.NoError(t, err)
+	assert.Empty(t, calls)
+	t.Log("ok > vault at", vault.Address.Hex(), "with token mock at", token.Address.Hex())
+}
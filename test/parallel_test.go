@@ -0,0 +1,56 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//Test to execute WemixToken from parallel subtests sharing one chain.
+func TestWemixParallel(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	senders := []*ecdsa.PrivateKey{}
+	for i := 0; i < 4; i++ {
+		key := newKey(t)
+		expecedSuccess(t, contract, nil, "transfer", crypto.PubkeyToAddress(key.PublicKey), big.NewInt(100))
+		senders = append(senders, key)
+	}
+	receiver := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	start := contract.Backend.Blockchain().CurrentBlock().NumberU64()
+
+	const transfers = 10
+	t.Run("group", func(t *testing.T) {
+		for _, key := range senders {
+			key := key
+			t.Run(crypto.PubkeyToAddress(key.PublicKey).Hex(), func(t *testing.T) {
+				t.Parallel()
+				for i := 0; i < transfers; i++ {
+					r, err := contract.Execute(key, "transfer", receiver, big.NewInt(1))
+					assert.NoError(t, err)
+					assert.True(t, r.Status == 1)
+				}
+			})
+		}
+		//the owner sends at the same time from its own goroutine
+		t.Run("owner", func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < transfers; i++ {
+				expecedSuccess(t, contract, nil, "transfer", receiver, big.NewInt(1))
+			}
+		})
+	})
+
+	//every transaction is mined alone in its own block
+	expected := int64(transfers * (len(senders) + 1))
+	assert.Equal(t, expected, call[*big.Int](t, contract, "balanceOf", receiver).Int64())
+	assert.Equal(t, start+uint64(expected), contract.Backend.Blockchain().CurrentBlock().NumberU64())
+	for _, key := range senders {
+		assert.Equal(t, int64(100-transfers), call[*big.Int](t, contract, "balanceOf", crypto.PubkeyToAddress(key.PublicKey)).Int64())
+	}
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test that the copies of a pool's fixture chain are isolated from each other.
+func TestWemixPool(t *testing.T) {
+	t.Parallel()
+	keys := backend.NewKeyRing(t.Name())
+	partner := crypto.PubkeyToAddress(keys.Key("partner").PublicKey)
+	ecoFund := crypto.PubkeyToAddress(keys.Key("ecoFund").PublicKey)
+	wemix := crypto.PubkeyToAddress(keys.Key("wemix").PublicKey)
+
+	setups := 0
+	pool, err := backend.NewPool("../contracts/WemixToken.sol", "WemixToken", func(c *backend.Contract) error {
+		setups++
+		return executeOk(c, nil, "addAllowedPartner", partner)
+	}, ecoFund, wemix)
+	assert.NoError(t, err)
+
+	first, second := getFixture(t, pool), getFixture(t, pool)
+	assert.Equal(t, 1, setups)
+	assert.Equal(t, first.Address, second.Address)
+	assert.NotEqual(t, first.Backend, second.Backend)
+	assert.True(t, call[bool](t, first, "allowedPartners", partner))
+	assert.True(t, call[bool](t, second, "allowedPartners", partner))
+
+	expecedSuccess(t, first, nil, "transfer", partner, big.NewInt(1))
+	assert.Equal(t, int64(1), call[*big.Int](t, first, "balanceOf", partner).Int64())
+	assert.Equal(t, int64(0), call[*big.Int](t, second, "balanceOf", partner).Int64())
+
+	t.Run("parallel", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			t.Run("copy", func(t *testing.T) {
+				t.Parallel()
+				c := getFixture(t, pool)
+				expecedSuccess(t, c, nil, "transfer", partner, big.NewInt(2))
+				assert.Equal(t, int64(2), call[*big.Int](t, c, "balanceOf", partner).Int64())
+			})
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to deploy a counter behind a proxy, and upgrade it keeping its state.
+func TestProxyUpgrade(t *testing.T) {
+	t.Parallel()
+	ownerKey := newKey(t)
+	owner := crypto.PubkeyToAddress(ownerKey.PublicKey)
+
+	v1, err := backend.NewContract("contracts/Counter.sol", "CounterV1")
+	assert.NoError(t, err)
+	proxy, err := backend.DeployProxy(v1, "initialize", owner, big.NewInt(5))
+	assert.NoError(t, err)
+	defer proxy.Backend.Close()
+
+	implementation, err := proxy.ImplementationAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.Address, implementation)
+	assert.NotEqual(t, v1.Address, proxy.Address)
+
+	//the state is in the proxy, not in the implementation
+	expecedSuccess(t, proxy.Contract, ownerKey, "increment")
+	assert.Equal(t, int64(6), call[*big.Int](t, proxy.Contract, "count").Int64())
+	assert.Equal(t, int64(0), call[*big.Int](t, v1, "count").Int64())
+	assert.Equal(t, "v1", call[string](t, proxy.Contract, "version"))
+	expecedFail(t, proxy.Contract, nil, "initialize", owner, big.NewInt(0))
+
+	//the implementation on another backend is rejected, and its backend is not closed
+	other, err := backend.NewContract("contracts/Counter.sol", "CounterV2")
+	assert.NoError(t, err)
+	defer other.Backend.Close()
+	assert.Error(t, proxy.Upgrade(other, ""))
+	assert.NoError(t, other.Deploy())
+
+	//only the admin upgrades
+	v2, err := backend.Compile("contracts/Counter.sol", "CounterV2")
+	assert.NoError(t, err)
+	stranger := *proxy
+	stranger.Admin = newKey(t)
+	assert.Error(t, stranger.Upgrade(v2, ""))
+
+	diffs, err := proxy.UpgradeAndCheck(v2, []backend.Probe{{Method: "count"}, {Method: "owner"}}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	implementation, err = proxy.ImplementationAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, v2.Address, implementation)
+	assert.Equal(t, "v2", call[string](t, proxy.Contract, "version"))
+
+	expecedFail(t, proxy.Contract, nil, "setStep", big.NewInt(10))
+	expecedSuccess(t, proxy.Contract, ownerKey, "setStep", big.NewInt(10))
+	expecedSuccess(t, proxy.Contract, ownerKey, "incrementByStep")
+	assert.Equal(t, int64(16), call[*big.Int](t, proxy.Contract, "count").Int64())
+	t.Log("ok > proxy at", proxy.Address.Hex(), "upgraded to", v2.Address.Hex())
+}
@@ -0,0 +1,12 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/wemade-tree/contract-test/scenario"
+)
+
+//Test to run the scenario files.
+func TestScenarios(t *testing.T) {
+	scenario.RunTests(t, "scenarios/*.yaml")
+}
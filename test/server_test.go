@@ -0,0 +1,304 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/wemade-tree/contract-test/backend"
+	"github.com/wemade-tree/contract-test/server"
+)
+
+//Test to use WemixToken deployed in Go through the JSON-RPC endpoint.
+func TestWemixServer(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	srv, err := server.New(contract.Backend)
+	assert.NoError(t, err)
+	defer srv.Close()
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	c, err := rpc.Dial(httpServer.URL)
+	assert.NoError(t, err)
+	defer c.Close()
+	client := ethclient.NewClient(c)
+	ctx := context.Background()
+
+	head, err := client.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.True(t, head.Number.Cmp(contract.BlockDeployed) == 0)
+
+	code, err := client.CodeAt(ctx, contract.Address, nil)
+	assert.NoError(t, err)
+	assert.True(t, len(code) > 0)
+
+	//eth_call
+	input, err := contract.Abi.Pack("balanceOf", contract.Owner)
+	assert.NoError(t, err)
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract.Address, Data: input}, nil)
+	assert.NoError(t, err)
+	values, err := contract.Abi.Methods["balanceOf"].Outputs.UnpackValues(out)
+	assert.NoError(t, err)
+	assert.True(t, values[0].(*big.Int).Cmp(call[*big.Int](t, contract, "balanceOf", contract.Owner)) == 0)
+
+	//eth_sendRawTransaction, mined at once
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+	input, err = contract.Abi.Pack("addAllowedPartner", partner)
+	assert.NoError(t, err)
+	nonce, err := client.PendingNonceAt(ctx, contract.Owner)
+	assert.NoError(t, err)
+	tx, err := types.SignTx(types.NewTransaction(nonce, contract.Address, new(big.Int), 1000000, new(big.Int), input),
+		types.HomesteadSigner{}, contract.OwnerKey)
+	assert.NoError(t, err)
+	assert.NoError(t, client.SendTransaction(ctx, tx))
+
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	assert.NoError(t, err)
+	assert.True(t, receipt.Status == 1)
+	assert.True(t, call[bool](t, contract, "allowedPartners", partner))
+
+	//the same nonce again
+	assert.Error(t, client.SendTransaction(ctx, tx))
+
+	//eth_getLogs
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{contract.Address}})
+	assert.NoError(t, err)
+	assert.True(t, len(logs) > 0)
+
+	//evm_snapshot and evm_revert
+	var id hexutil.Uint64
+	assert.NoError(t, c.Call(&id, "evm_snapshot"))
+	var block hexutil.Uint64
+	assert.NoError(t, c.Call(&block, "evm_mine"))
+	assert.Equal(t, receipt.BlockNumber.Uint64()+1, uint64(block))
+
+	var reverted bool
+	assert.NoError(t, c.Call(&reverted, "evm_revert", id))
+	assert.True(t, reverted)
+	head, err = client.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.BlockNumber.Uint64(), head.Number.Uint64())
+	t.Log("ok > served up to block", head.Number)
+}
+
+//Test to get the reason and the data of a reverted eth_call as a JSON-RPC error.
+func TestWemixServerCallRevert(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	srv, err := server.New(contract.Backend)
+	assert.NoError(t, err)
+	defer srv.Close()
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	reason, err := contract.RevertReason(contract.Owner, "withdraw", big.NewInt(99))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reason)
+	input, err := contract.Abi.Pack("withdraw", big.NewInt(99))
+	assert.NoError(t, err)
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_call",
+		"params": []interface{}{
+			map[string]interface{}{"from": contract.Owner, "to": contract.Address, "data": hexutil.Bytes(input)},
+			"latest",
+		},
+	})
+	assert.NoError(t, err)
+	resp, err := http.Post(httpServer.URL, "application/json", bytes.NewReader(request))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	response := struct {
+		Result *hexutil.Bytes
+		Error  *struct {
+			Code    int
+			Message string
+			Data    hexutil.Bytes
+		}
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Nil(t, response.Result)
+	if assert.NotNil(t, response.Error) {
+		assert.Equal(t, 3, response.Error.Code)
+		assert.Equal(t, "execution reverted: "+reason, response.Error.Message)
+		unpacked, ok := backend.UnpackRevert(response.Error.Data)
+		assert.True(t, ok)
+		assert.Equal(t, reason, unpacked)
+	}
+
+	//through the client
+	c, err := rpc.Dial(httpServer.URL)
+	assert.NoError(t, err)
+	defer c.Close()
+	_, err = ethclient.NewClient(c).CallContract(context.Background(), ethereum.CallMsg{From: contract.Owner, To: &contract.Address, Data: input}, nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "execution reverted: "+reason, err.Error())
+	}
+	t.Log("ok > reverted:", reason)
+}
+
+//Test to revert to a snapshot taken after evm_increaseTime, and get the same head block.
+func TestWemixServerRevertTime(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	srv, err := server.New(contract.Backend)
+	assert.NoError(t, err)
+	defer srv.Close()
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	c, err := rpc.Dial(httpServer.URL)
+	assert.NoError(t, err)
+	defer c.Close()
+	client := ethclient.NewClient(c)
+	ctx := context.Background()
+
+	before, err := client.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Call(nil, "evm_increaseTime", hexutil.Uint64(3600)))
+	var block hexutil.Uint64
+	assert.NoError(t, c.Call(&block, "evm_mine"))
+	head, err := client.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, before.Time+3610, head.Time)
+
+	var id hexutil.Uint64
+	assert.NoError(t, c.Call(&id, "evm_snapshot"))
+	assert.NoError(t, c.Call(nil, "evm_increaseTime", hexutil.Uint64(60)))
+	assert.NoError(t, c.Call(&block, "evm_mine"))
+
+	var reverted bool
+	assert.NoError(t, c.Call(&reverted, "evm_revert", id))
+	assert.True(t, reverted)
+	restored, err := client.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, head.Time, restored.Time)
+	assert.Equal(t, head.Hash(), restored.Hash())
+	t.Log("ok > reverted to block", restored.Number, "at", restored.Time)
+}
+
+//receive returns the value received from the channel in the timeout.
+func receive[T any](ch <-chan T, timeout time.Duration) (T, bool) {
+	select {
+	case v := <-ch:
+		return v, true
+	case <-time.After(timeout):
+		var zero T
+		return zero, false
+	}
+}
+
+//Test to receive newHeads and logs over WebSocket, before and after evm_revert replaces the backend.
+func TestWemixServerSubscribe(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	srv, err := server.New(contract.Backend)
+	assert.NoError(t, err)
+	defer srv.Close()
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	c, err := rpc.Dial("ws" + strings.TrimPrefix(httpServer.URL, "http"))
+	assert.NoError(t, err)
+	defer c.Close()
+	client := ethclient.NewClient(c)
+	ctx := context.Background()
+
+	heads := make(chan *types.Header, 100)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	assert.NoError(t, err)
+	defer headSub.Unsubscribe()
+	logs := make(chan types.Log, 100)
+	logSub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{contract.Address}}, logs)
+	assert.NoError(t, err)
+	defer logSub.Unsubscribe()
+
+	partner := crypto.PubkeyToAddress(newKey(t).PublicKey)
+	transfer := func() common.Hash {
+		input, err := contract.Abi.Pack("transfer", partner, big.NewInt(1))
+		assert.NoError(t, err)
+		nonce, err := client.PendingNonceAt(ctx, contract.Owner)
+		assert.NoError(t, err)
+		tx, err := types.SignTx(types.NewTransaction(nonce, contract.Address, new(big.Int), 1000000, new(big.Int), input),
+			types.HomesteadSigner{}, contract.OwnerKey)
+		assert.NoError(t, err)
+		assert.NoError(t, client.SendTransaction(ctx, tx))
+		return tx.Hash()
+	}
+
+	hash := transfer()
+	head, ok := receive(heads, 5*time.Second)
+	if assert.True(t, ok, "no newHeads notification") {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		assert.NoError(t, err)
+		assert.Equal(t, receipt.BlockNumber.Uint64(), head.Number.Uint64())
+	}
+	log, ok := receive(logs, 5*time.Second)
+	if assert.True(t, ok, "no logs notification") {
+		assert.Equal(t, hash, log.TxHash)
+		assert.Equal(t, contract.Abi.Events["Transfer"].Id(), log.Topics[0])
+	}
+
+	var id hexutil.Uint64
+	assert.NoError(t, c.Call(&id, "evm_snapshot"))
+	var reverted bool
+	assert.NoError(t, c.Call(&reverted, "evm_revert", id))
+	assert.True(t, reverted)
+
+	//the subscriptions are made again on the new backend in the background
+	for i := 0; ; i++ {
+		if i == 50 {
+			t.Fatal("no newHeads notification after evm_revert")
+		}
+		var block hexutil.Uint64
+		assert.NoError(t, c.Call(&block, "evm_mine"))
+		if head, ok := receive(heads, 100*time.Millisecond); ok == true {
+			assert.True(t, head.Number.Uint64() <= uint64(block))
+			break
+		}
+	}
+	sent := map[common.Hash]bool{}
+	for i := 0; ; i++ {
+		if i == 50 {
+			t.Fatal("no logs notification after evm_revert")
+		}
+		sent[transfer()] = true
+		if log, ok := receive(logs, 100*time.Millisecond); ok == true {
+			assert.True(t, sent[log.TxHash])
+			break
+		}
+	}
+
+	select {
+	case err := <-headSub.Err():
+		t.Fatal(err)
+	case err := <-logSub.Err():
+		t.Fatal(err)
+	default:
+	}
+	t.Log("ok > notified after evm_revert")
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test that WemixToken keeps its code size and deploy gas within the limits.
+func TestWemixSize(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	report, err := contract.Size()
+	assert.NoError(t, err)
+	t.Log(report)
+
+	assert.True(t, report.CodeSize > 0)
+	assert.True(t, report.DeployGas > 0)
+	assert.NoError(t, report.Check(backend.DefaultSizeLimits))
+
+	limits := backend.DefaultSizeLimits
+	limits.DeployGas = 3000000 //gas limit of the deploy tx
+	assert.NoError(t, report.Check(limits))
+
+	//the estimate before deploying is close to the gas used
+	estimated, err := contract.Clone().Size(contract.ConstructorInputs...)
+	assert.NoError(t, err)
+	assert.True(t, estimated.DeployGas >= report.DeployGas)
+	assert.Equal(t, report.InitCodeSize, estimated.InitCodeSize)
+
+	limits.CodeSize = report.CodeSize - 1
+	assert.Error(t, report.Check(limits))
+}
+
+//Test to report a contract over the code size limit without estimating its deploy gas.
+func TestSizeOversized(t *testing.T) {
+	t.Parallel()
+	//the literal is in the runtime code
+	source := fmt.Sprintf(`pragma solidity >= 0.6.0 <0.7.0;
+
+contract Oversized {
+    function blob() public pure returns (bytes memory) {
+        return hex"%s";
+    }
+}
+`, strings.Repeat("ab", backend.MaxCodeSize+1))
+	path := filepath.Join(t.TempDir(), "Oversized.sol")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(source), 0644))
+
+	contract, err := backend.NewContract(path, "Oversized")
+	assert.NoError(t, err)
+	defer contract.Backend.Close()
+
+	report, err := contract.Size()
+	assert.NoError(t, err)
+	t.Log(report)
+	assert.True(t, report.CodeSize > backend.MaxCodeSize)
+	assert.True(t, report.GasUnknown)
+	assert.Contains(t, report.String(), "deploy gas unknown")
+
+	limits := backend.DefaultSizeLimits
+	limits.DeployGas = 3000000
+	err = report.Check(limits)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "code size")
+		assert.NotContains(t, err.Error(), "deploy gas")
+	}
+}
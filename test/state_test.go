@@ -0,0 +1,110 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to export the state after staking, and import it on a new chain.
+func TestWemixExportState(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	partnerKey := newKey(t)
+	partner := crypto.PubkeyToAddress(partnerKey.PublicKey)
+	expecedSuccess(t, contract, nil, "addAllowedPartner", partner)
+	expecedSuccess(t, contract, nil, "stakeDelegated", partner, new(big.Int))
+	//the head time is not a multiple of the block interval
+	assert.NoError(t, contract.Backend.AdjustTime(time.Hour+3*time.Second))
+	contract.Backend.Commit()
+
+	path := filepath.Join(t.TempDir(), "wemix-genesis.json")
+	assert.NoError(t, backend.ExportState(contract.Backend, path, map[string]*backend.Contract{"wemix": contract}))
+
+	b, contracts, err := backend.ImportState(path)
+	assert.NoError(t, err)
+	defer b.Close()
+	imported, ok := contracts["wemix"]
+	assert.True(t, ok)
+	assert.Equal(t, contract.Address, imported.Address)
+	assert.Equal(t, contract.Owner, imported.Owner)
+	assert.Equal(t, contract.Backend.Blockchain().CurrentBlock().NumberU64(), b.Blockchain().CurrentBlock().NumberU64())
+	assert.Equal(t, contract.Backend.Blockchain().CurrentBlock().Time(), b.Blockchain().CurrentBlock().Time())
+
+	for _, method := range []string{"totalSupply", "partnersNumber"} {
+		assert.Equal(t, call[*big.Int](t, contract, method), call[*big.Int](t, imported, method), method)
+	}
+	assert.Equal(t, call[*big.Int](t, contract, "balanceOf", contract.Owner), call[*big.Int](t, imported, "balanceOf", imported.Owner))
+
+	//the imported chain goes on with the owner's nonce
+	other := newKey(t)
+	expecedSuccess(t, imported, nil, "addAllowedPartner", crypto.PubkeyToAddress(other.PublicKey))
+	t.Log("ok > state imported at block", b.Blockchain().CurrentBlock().Number())
+}
+
+//Test to reject a state file whose chain config differs from the simulated one.
+func TestWemixImportStateConfig(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	path := filepath.Join(t.TempDir(), "wemix-genesis.json")
+	assert.NoError(t, backend.ExportState(contract.Backend, path, map[string]*backend.Contract{"wemix": contract}))
+
+	raw, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	genesis := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(raw, &genesis))
+	genesis["config"].(map[string]interface{})["chainId"] = 1234
+	raw, err = json.Marshal(genesis)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, raw, 0644))
+
+	_, _, err = backend.ImportState(path)
+	assert.Error(t, err)
+	t.Log("ok > rejected:", err)
+}
+
+//Test to import a state file at a large block number at once, and go on from there.
+func TestWemixImportStateNumber(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+
+	path := filepath.Join(t.TempDir(), "wemix-genesis.json")
+	assert.NoError(t, backend.ExportState(contract.Backend, path, map[string]*backend.Contract{"wemix": contract}))
+
+	raw, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	genesis := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(raw, &genesis))
+	genesis["number"] = "0x989680"     //10,000,000
+	genesis["timestamp"] = "0x5f5e100" //100,000,000
+	raw, err = json.Marshal(genesis)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, raw, 0644))
+
+	start := time.Now()
+	b, contracts, err := backend.ImportState(path)
+	assert.NoError(t, err)
+	defer b.Close()
+	assert.True(t, time.Since(start) < 10*time.Second, "imported in %s", time.Since(start))
+	head := b.Blockchain().CurrentBlock()
+	assert.Equal(t, uint64(10000000), head.NumberU64())
+	assert.Equal(t, uint64(100000000), head.Time())
+
+	imported := contracts["wemix"]
+	assert.Equal(t, call[*big.Int](t, contract, "totalSupply"), call[*big.Int](t, imported, "totalSupply"))
+	other := newKey(t)
+	expecedSuccess(t, imported, nil, "addAllowedPartner", crypto.PubkeyToAddress(other.PublicKey))
+	head = b.Blockchain().CurrentBlock()
+	assert.Equal(t, uint64(10000001), head.NumberU64())
+	assert.Equal(t, uint64(100000010), head.Time())
+}
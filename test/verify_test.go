@@ -0,0 +1,35 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/wemade-tree/contract-test/backend"
+)
+
+//Test to verify the deployed code of WemixToken against the compiled code and the saved artifact.
+func TestWemixVerify(t *testing.T) {
+	t.Parallel()
+	contract := depolyWemix(t)
+	assert.NoError(t, contract.Verify())
+
+	path := filepath.Join(t.TempDir(), "WemixToken.json")
+	assert.NoError(t, contract.Save(path))
+	assert.NoError(t, backend.VerifyArtifact(path, nil))
+	assert.NoError(t, backend.VerifyArtifact(path, contract.Backend))
+
+	//a changed opcode is found
+	tampered := *contract
+	tampered.RuntimeCode = append([]byte{}, contract.RuntimeCode...)
+	tampered.RuntimeCode[0] ^= 0xff
+	assert.Error(t, tampered.Verify())
+
+	//no code at the address
+	tampered = *contract
+	tampered.Address = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	assert.Error(t, tampered.Verify())
+	t.Log("ok > verified code at", contract.Address.Hex())
+}